@@ -0,0 +1,290 @@
+package mandrill
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Client is the set of api domains provided by *Mandrill. Depend on it, or on a single
+// domain interface, to substitute a mock in tests
+type Client interface {
+	Users() UsersAPI
+	Messages() MessagesAPI
+	Tags() TagsAPI
+	Rejects() RejectsAPI
+	Whitelists() WhitelistsAPI
+	Senders() SendersAPI
+	URLs() URLsAPI
+	Templates() TemplatesAPI
+	Webhooks() WebhooksAPI
+	Subaccounts() SubaccountsAPI
+	Inbound() InboundAPI
+	Exports() ExportsAPI
+	IPs() IPsAPI
+	Metadata() MetadataAPI
+}
+
+// compile time checks that the concrete types satisfy their interfaces
+var (
+	_ Client         = (*Mandrill)(nil)
+	_ UsersAPI       = (*Users)(nil)
+	_ MessageSender  = (*Messages)(nil)
+	_ MessageSender  = (*SMTPSender)(nil)
+	_ MessagesAPI    = (*Messages)(nil)
+	_ TagsAPI        = (*Tags)(nil)
+	_ RejectsAPI     = (*Rejects)(nil)
+	_ WhitelistsAPI  = (*Whitelists)(nil)
+	_ SendersAPI     = (*Senders)(nil)
+	_ URLsAPI        = (*URLs)(nil)
+	_ TemplatesAPI   = (*Templates)(nil)
+	_ WebhooksAPI    = (*Webhooks)(nil)
+	_ SubaccountsAPI = (*Subaccounts)(nil)
+	_ InboundAPI     = (*Inbound)(nil)
+	_ ExportsAPI     = (*Exports)(nil)
+	_ IPsAPI         = (*IPs)(nil)
+	_ MetadataAPI    = (*Metadata)(nil)
+)
+
+// UsersAPI is implemented by *Users
+type UsersAPI interface {
+	Info() (InfoResponse, error)
+	InfoContext(ctx context.Context) (InfoResponse, error)
+	Ping() (bool, error)
+	PingContext(ctx context.Context) (bool, error)
+	Senders() ([]Sender, error)
+	SendersContext(ctx context.Context) ([]Sender, error)
+}
+
+// MessageSender sends messages. It is implemented by *Messages, through the api,
+// and by *SMTPSender, through the SMTP relay
+type MessageSender interface {
+	Send(message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error)
+	SendContext(ctx context.Context, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error)
+}
+
+// MessagesAPI is implemented by *Messages
+type MessagesAPI interface {
+	Send(message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error)
+	SendContext(ctx context.Context, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error)
+	SendTemplate(templateName string, templateContent []TemplateMergeVar, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error)
+	SendTemplateContext(ctx context.Context, templateName string, templateContent []TemplateMergeVar, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error)
+	SendRaw(rawMessage string, r *MessagesSendRawRequest) ([]SendResponse, error)
+	SendRawContext(ctx context.Context, rawMessage string, r *MessagesSendRawRequest) ([]SendResponse, error)
+	SendRawReader(rawMessage io.Reader, r *MessagesSendRawRequest) ([]SendResponse, error)
+	SendRawReaderContext(ctx context.Context, rawMessage io.Reader, r *MessagesSendRawRequest) ([]SendResponse, error)
+	Parse(rawMessage string) (*Message, error)
+	ParseContext(ctx context.Context, rawMessage string) (*Message, error)
+	Search(r *MessagesSearchRequest) ([]MessageInfo, error)
+	SearchContext(ctx context.Context, r *MessagesSearchRequest) ([]MessageInfo, error)
+	SearchTimeSeries(r *MessagesSearchRequest) ([]MessagesTimeSeries, error)
+	SearchTimeSeriesContext(ctx context.Context, r *MessagesSearchRequest) ([]MessagesTimeSeries, error)
+	Info(id string) (MessageInfo, error)
+	InfoContext(ctx context.Context, id string) (MessageInfo, error)
+	Content(id string) (MessageContent, error)
+	ContentContext(ctx context.Context, id string) (MessageContent, error)
+	ListScheduled(to string) ([]ScheduledMessage, error)
+	ListScheduledContext(ctx context.Context, to string) ([]ScheduledMessage, error)
+	CancelScheduled(id string) (ScheduledMessage, error)
+	CancelScheduledContext(ctx context.Context, id string) (ScheduledMessage, error)
+	Reschedule(id string, sendAt time.Time) (ScheduledMessage, error)
+	RescheduleContext(ctx context.Context, id string, sendAt time.Time) (ScheduledMessage, error)
+}
+
+// TagsAPI is implemented by *Tags
+type TagsAPI interface {
+	List() ([]TagInfo, error)
+	ListContext(ctx context.Context) ([]TagInfo, error)
+	Delete(tag string) (TagInfo, error)
+	DeleteContext(ctx context.Context, tag string) (TagInfo, error)
+	Info(tag string) (TagStats, error)
+	InfoContext(ctx context.Context, tag string) (TagStats, error)
+	TimeSeries(tag string) ([]TagTimeSeries, error)
+	TimeSeriesContext(ctx context.Context, tag string) ([]TagTimeSeries, error)
+	AllTimeSeries() ([]TagTimeSeries, error)
+	AllTimeSeriesContext(ctx context.Context) ([]TagTimeSeries, error)
+}
+
+// RejectsAPI is implemented by *Rejects
+type RejectsAPI interface {
+	Add(email string, comment string, subaccount string) (RejectsAddResponse, error)
+	AddContext(ctx context.Context, email string, comment string, subaccount string) (RejectsAddResponse, error)
+	Delete(email string, subaccount string) (RejectsDeleteResponse, error)
+	DeleteContext(ctx context.Context, email string, subaccount string) (RejectsDeleteResponse, error)
+	List(email string, expired bool, subaccount string) ([]RejectsListResponse, error)
+	ListContext(ctx context.Context, email string, expired bool, subaccount string) ([]RejectsListResponse, error)
+}
+
+// WhitelistsAPI is implemented by *Whitelists
+type WhitelistsAPI interface {
+	Add(email string, comment string) (WhitelistsAddResponse, error)
+	AddContext(ctx context.Context, email string, comment string) (WhitelistsAddResponse, error)
+	Delete(email string) (WhitelistsDeleteResponse, error)
+	DeleteContext(ctx context.Context, email string) (WhitelistsDeleteResponse, error)
+	List(email string) ([]WhitelistsListResponse, error)
+	ListContext(ctx context.Context, email string) ([]WhitelistsListResponse, error)
+}
+
+// SendersAPI is implemented by *Senders
+type SendersAPI interface {
+	List() ([]SendersListResponse, error)
+	ListContext(ctx context.Context) ([]SendersListResponse, error)
+	Domains() ([]SendersDomain, error)
+	DomainsContext(ctx context.Context) ([]SendersDomain, error)
+	AddDomain(domain string) (SendersDomain, error)
+	AddDomainContext(ctx context.Context, domain string) (SendersDomain, error)
+	CheckDomain(domain string) (SendersDomain, error)
+	CheckDomainContext(ctx context.Context, domain string) (SendersDomain, error)
+	VerifyDomain(domain string, mailbox string) (SendersVerifyResponse, error)
+	VerifyDomainContext(ctx context.Context, domain string, mailbox string) (SendersVerifyResponse, error)
+	Info(address string) (SendersInfoResponse, error)
+	InfoContext(ctx context.Context, address string) (SendersInfoResponse, error)
+	TimeSeries(address string) ([]SenderTimeSeries, error)
+	TimeSeriesContext(ctx context.Context, address string) ([]SenderTimeSeries, error)
+}
+
+// URLsAPI is implemented by *URLs
+type URLsAPI interface {
+	List() ([]URLsResponse, error)
+	ListContext(ctx context.Context) ([]URLsResponse, error)
+	Search(query string) ([]URLsResponse, error)
+	SearchContext(ctx context.Context, query string) ([]URLsResponse, error)
+	TimeSeries(url string) ([]URLsTimeSeriesResponse, error)
+	TimeSeriesContext(ctx context.Context, url string) ([]URLsTimeSeriesResponse, error)
+	TrackingDomains() ([]URLsTrackingDomainResponse, error)
+	TrackingDomainsContext(ctx context.Context) ([]URLsTrackingDomainResponse, error)
+	AddTrackingDomain(domain string) (URLsTrackingDomainResponse, error)
+	AddTrackingDomainContext(ctx context.Context, domain string) (URLsTrackingDomainResponse, error)
+	CheckTrackingDomain(domain string) (URLsTrackingDomainResponse, error)
+	CheckTrackingDomainContext(ctx context.Context, domain string) (URLsTrackingDomainResponse, error)
+}
+
+// TemplatesAPI is implemented by *Templates
+type TemplatesAPI interface {
+	Add(template *Template) (TemplateResponse, error)
+	AddContext(ctx context.Context, template *Template) (TemplateResponse, error)
+	Info(name string) (TemplateResponse, error)
+	InfoContext(ctx context.Context, name string) (TemplateResponse, error)
+	Update(template *Template) (TemplateResponse, error)
+	UpdateContext(ctx context.Context, template *Template) (TemplateResponse, error)
+	Publish(name string) (TemplateResponse, error)
+	PublishContext(ctx context.Context, name string) (TemplateResponse, error)
+	Delete(name string) (TemplateResponse, error)
+	DeleteContext(ctx context.Context, name string) (TemplateResponse, error)
+	List(label string) ([]TemplateResponse, error)
+	ListContext(ctx context.Context, label string) ([]TemplateResponse, error)
+	TimeSeries(name string) ([]TemplatesTimeSeries, error)
+	TimeSeriesContext(ctx context.Context, name string) ([]TemplatesTimeSeries, error)
+	Render(r *TemplatesRenderRequest) (string, error)
+	RenderContext(ctx context.Context, r *TemplatesRenderRequest) (string, error)
+}
+
+// WebhooksAPI is implemented by *Webhooks
+type WebhooksAPI interface {
+	List() ([]WebhooksResponse, error)
+	ListContext(ctx context.Context) ([]WebhooksResponse, error)
+	Add(url string, description string, events []string) (WebhooksResponse, error)
+	AddContext(ctx context.Context, url string, description string, events []string) (WebhooksResponse, error)
+	Info(id int) (WebhooksResponse, error)
+	InfoContext(ctx context.Context, id int) (WebhooksResponse, error)
+	Update(id int, url string, description string, events []string) (WebhooksResponse, error)
+	UpdateContext(ctx context.Context, id int, url string, description string, events []string) (WebhooksResponse, error)
+	Delete(id int) (WebhooksResponse, error)
+	DeleteContext(ctx context.Context, id int) (WebhooksResponse, error)
+}
+
+// SubaccountsAPI is implemented by *Subaccounts
+type SubaccountsAPI interface {
+	List(q string) ([]SubaccountsResponse, error)
+	ListContext(ctx context.Context, q string) ([]SubaccountsResponse, error)
+	Add(id string, name string, notes string, quota int) (SubaccountsResponse, error)
+	AddContext(ctx context.Context, id string, name string, notes string, quota int) (SubaccountsResponse, error)
+	Info(id string) (SubaccountsInfoResponse, error)
+	InfoContext(ctx context.Context, id string) (SubaccountsInfoResponse, error)
+	Update(id string, name string, notes string, quota int) (SubaccountsResponse, error)
+	UpdateContext(ctx context.Context, id string, name string, notes string, quota int) (SubaccountsResponse, error)
+	Delete(id string) (SubaccountsResponse, error)
+	DeleteContext(ctx context.Context, id string) (SubaccountsResponse, error)
+	Pause(id string) (SubaccountsResponse, error)
+	PauseContext(ctx context.Context, id string) (SubaccountsResponse, error)
+	Resume(id string) (SubaccountsResponse, error)
+	ResumeContext(ctx context.Context, id string) (SubaccountsResponse, error)
+}
+
+// InboundAPI is implemented by *Inbound
+type InboundAPI interface {
+	Domains() ([]InboundDomainResponse, error)
+	DomainsContext(ctx context.Context) ([]InboundDomainResponse, error)
+	AddDomain(domain string) (InboundDomainResponse, error)
+	AddDomainContext(ctx context.Context, domain string) (InboundDomainResponse, error)
+	CheckDomain(domain string) (InboundDomainResponse, error)
+	CheckDomainContext(ctx context.Context, domain string) (InboundDomainResponse, error)
+	DeleteDomain(domain string) (InboundDomainResponse, error)
+	DeleteDomainContext(ctx context.Context, domain string) (InboundDomainResponse, error)
+	Routes(domain string) ([]InboundRouteResponse, error)
+	RoutesContext(ctx context.Context, domain string) ([]InboundRouteResponse, error)
+	AddRoute(domain string, pattern string, url string) (InboundRouteResponse, error)
+	AddRouteContext(ctx context.Context, domain string, pattern string, url string) (InboundRouteResponse, error)
+	UpdateRoute(id string, pattern string, url string) (InboundRouteResponse, error)
+	UpdateRouteContext(ctx context.Context, id string, pattern string, url string) (InboundRouteResponse, error)
+	DeleteRoute(id string) (InboundRouteResponse, error)
+	DeleteRouteContext(ctx context.Context, id string) (InboundRouteResponse, error)
+	SendRaw(rawMessage string, to []string, from string, helo string, clientAddr string) ([]InboundSendRawResponse, error)
+	SendRawContext(ctx context.Context, rawMessage string, to []string, from string, helo string, clientAddr string) ([]InboundSendRawResponse, error)
+}
+
+// ExportsAPI is implemented by *Exports
+type ExportsAPI interface {
+	Info(id string) (ExportsResponse, error)
+	InfoContext(ctx context.Context, id string) (ExportsResponse, error)
+	List() ([]ExportsResponse, error)
+	ListContext(ctx context.Context) ([]ExportsResponse, error)
+	Rejects(notifyEmail string) (ExportsResponse, error)
+	RejectsContext(ctx context.Context, notifyEmail string) (ExportsResponse, error)
+	Whitelist(notifyEmail string) (ExportsResponse, error)
+	WhitelistContext(ctx context.Context, notifyEmail string) (ExportsResponse, error)
+	Activity(request *ExportActivityRequest) (ExportsResponse, error)
+	ActivityContext(ctx context.Context, request *ExportActivityRequest) (ExportsResponse, error)
+}
+
+// IPsAPI is implemented by *IPs
+type IPsAPI interface {
+	List() ([]IPsResponse, error)
+	ListContext(ctx context.Context) ([]IPsResponse, error)
+	Info(ip string) (IPsResponse, error)
+	InfoContext(ctx context.Context, ip string) (IPsResponse, error)
+	Provision(warmup bool, pool string) (IPsProvisionResponse, error)
+	ProvisionContext(ctx context.Context, warmup bool, pool string) (IPsProvisionResponse, error)
+	StartWarmup(ip string) (IPsResponse, error)
+	StartWarmupContext(ctx context.Context, ip string) (IPsResponse, error)
+	CancelWarmup(ip string) (IPsResponse, error)
+	CancelWarmupContext(ctx context.Context, ip string) (IPsResponse, error)
+	SetPool(ip string, pool string, createPool bool) (IPsResponse, error)
+	SetPoolContext(ctx context.Context, ip string, pool string, createPool bool) (IPsResponse, error)
+	Delete(ip string) (IPsDeleteResponse, error)
+	DeleteContext(ctx context.Context, ip string) (IPsDeleteResponse, error)
+	ListPools() ([]IPsPoolsResponse, error)
+	ListPoolsContext(ctx context.Context) ([]IPsPoolsResponse, error)
+	PoolInfo(pool string) (IPsPoolsResponse, error)
+	PoolInfoContext(ctx context.Context, pool string) (IPsPoolsResponse, error)
+	CreatePool(pool string) (IPsPoolsResponse, error)
+	CreatePoolContext(ctx context.Context, pool string) (IPsPoolsResponse, error)
+	DeletePool(pool string) (IPsDeletePoolResponse, error)
+	DeletePoolContext(ctx context.Context, pool string) (IPsDeletePoolResponse, error)
+	CheckCustomDNS(ip string, domain string) (IPsCheckDNSResponse, error)
+	CheckCustomDNSContext(ctx context.Context, ip string, domain string) (IPsCheckDNSResponse, error)
+	SetCustomDNS(ip string, domain string) (IPsResponse, error)
+	SetCustomDNSContext(ctx context.Context, ip string, domain string) (IPsResponse, error)
+}
+
+// MetadataAPI is implemented by *Metadata
+type MetadataAPI interface {
+	List() ([]MetadataResponse, error)
+	ListContext(ctx context.Context) ([]MetadataResponse, error)
+	Add(name string, viewTemplate string) (MetadataResponse, error)
+	AddContext(ctx context.Context, name string, viewTemplate string) (MetadataResponse, error)
+	Update(name string, viewTemplate string) (MetadataResponse, error)
+	UpdateContext(ctx context.Context, name string, viewTemplate string) (MetadataResponse, error)
+	Delete(name string) (MetadataResponse, error)
+	DeleteContext(ctx context.Context, name string) (MetadataResponse, error)
+}
@@ -0,0 +1,42 @@
+package mandrill
+
+import (
+	"testing"
+	"time"
+)
+
+type mockMessages struct {
+	MessagesAPI
+	sent []*Message
+}
+
+func (m *mockMessages) Send(message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	m.sent = append(m.sent, message)
+	return []SendResponse{{Email: message.To[0].Email, Status: "sent"}}, nil
+}
+
+type mockClient struct {
+	Client
+	messages *mockMessages
+}
+
+func (c *mockClient) Messages() MessagesAPI {
+	return c.messages
+}
+
+func TestClientMock(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	c := &mockClient{Client: &m, messages: &mockMessages{}}
+
+	notify := func(c Client) error {
+		_, err := c.Messages().Send(&Message{To: []Recipient{{Email: "jane@example.com"}}}, false, "", nil)
+		return err
+	}
+	if err := notify(c); err != nil {
+		t.Error(err)
+		return
+	}
+	if len(c.messages.sent) != 1 || c.messages.sent[0].To[0].Email != "jane@example.com" {
+		t.Errorf("expected message sent through mock. Received: %+v", c.messages.sent)
+	}
+}
@@ -0,0 +1,179 @@
+package mandrill
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxAttachmentSize is the largest attachment or image, before encoding, accepted by the
+// constructors below unless changed with WithMaxSize. Mandrill rejects messages larger than 25MB in total
+const DefaultMaxAttachmentSize int64 = 25 * 1024 * 1024
+
+// ErrAttachmentTooLarge is returned when content exceeds the maximum attachment size
+var ErrAttachmentTooLarge = errors.New("mandrill: attachment exceeds the maximum size")
+
+// AttachmentOption configures the attachment and image constructors
+type AttachmentOption func(*attachmentConfig)
+
+type attachmentConfig struct {
+	maxSize int64
+}
+
+// WithMaxSize sets the largest content accepted, before encoding, instead of DefaultMaxAttachmentSize
+func WithMaxSize(n int64) AttachmentOption {
+	return func(c *attachmentConfig) {
+		c.maxSize = n
+	}
+}
+
+func newAttachmentConfig(opts []AttachmentOption) *attachmentConfig {
+	c := &attachmentConfig{maxSize: DefaultMaxAttachmentSize}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewAttachment returns an attachment named name with the content of r. The MIME type is
+// taken from the extension of name, or sniffed from the content if the extension is unknown
+func NewAttachment(name string, r io.Reader, opts ...AttachmentOption) (*Attachment, error) {
+	typ, content, err := encodeContent(name, r, 0, newAttachmentConfig(opts).maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Attachment{Name: name, Type: typ, Content: content}, nil
+}
+
+// NewAttachmentFromFile returns an attachment with the content of the file at filename,
+// named after its base name
+func NewAttachmentFromFile(filename string, opts ...AttachmentOption) (*Attachment, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return newAttachmentFromFile(filepath.Base(filename), f, opts)
+}
+
+// NewAttachmentFromFS returns an attachment with the content of the file name in fsys,
+// such as an embed.FS, named after its base name
+func NewAttachmentFromFS(fsys fs.FS, name string, opts ...AttachmentOption) (*Attachment, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return newAttachmentFromFile(path.Base(name), f, opts)
+}
+
+func newAttachmentFromFile(name string, f fs.File, opts []AttachmentOption) (*Attachment, error) {
+	typ, content, err := encodeFile(name, f, newAttachmentConfig(opts).maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Attachment{Name: name, Type: typ, Content: content}, nil
+}
+
+// NewImage returns an embedded image with content id name and the content of r.
+// The MIME type is detected as for NewAttachment and must start with "image/"
+func NewImage(name string, r io.Reader, opts ...AttachmentOption) (*Image, error) {
+	typ, content, err := encodeContent(name, r, 0, newAttachmentConfig(opts).maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return newImage(name, typ, content)
+}
+
+// NewImageFromFile returns an embedded image with the content of the file at filename,
+// with its base name as the content id
+func NewImageFromFile(filename string, opts ...AttachmentOption) (*Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return newImageFromFile(filepath.Base(filename), f, opts)
+}
+
+// NewImageFromFS returns an embedded image with the content of the file name in fsys,
+// with its base name as the content id
+func NewImageFromFS(fsys fs.FS, name string, opts ...AttachmentOption) (*Image, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return newImageFromFile(path.Base(name), f, opts)
+}
+
+func newImageFromFile(name string, f fs.File, opts []AttachmentOption) (*Image, error) {
+	typ, content, err := encodeFile(name, f, newAttachmentConfig(opts).maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return newImage(name, typ, content)
+}
+
+func newImage(name, typ, content string) (*Image, error) {
+	if !strings.HasPrefix(typ, "image/") {
+		return nil, fmt.Errorf("mandrill: image %q has type %q, which does not start with \"image/\"", name, typ)
+	}
+	return &Image{Name: name, Type: typ, Content: content}, nil
+}
+
+// encodeFile is like encodeContent, checking the file's size before reading it
+func encodeFile(name string, f fs.File, maxSize int64) (string, string, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return "", "", err
+	}
+	if fi.IsDir() {
+		return "", "", fmt.Errorf("mandrill: %q is a directory", name)
+	}
+	if fi.Size() > maxSize {
+		return "", "", ErrAttachmentTooLarge
+	}
+	return encodeContent(name, f, fi.Size(), maxSize)
+}
+
+// encodeContent returns the MIME type and base64 encoded content of r, of at most maxSize bytes.
+// The content is encoded as it is read, so only the encoded copy is held in memory. size, if
+// known, preallocates the encoded string
+func encodeContent(name string, r io.Reader, size, maxSize int64) (string, string, error) {
+	br := bufio.NewReaderSize(r, 512)
+	typ := mime.TypeByExtension(path.Ext(name))
+	if typ == "" {
+		head, err := br.Peek(512)
+		if err != nil && err != io.EOF {
+			return "", "", err
+		}
+		typ = http.DetectContentType(head)
+	}
+
+	var sb strings.Builder
+	if size > 0 {
+		sb.Grow(base64.StdEncoding.EncodedLen(int(size)))
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, &sb)
+	n, err := io.Copy(enc, io.LimitReader(br, maxSize+1))
+	if err != nil {
+		return "", "", err
+	}
+	if n > maxSize {
+		return "", "", ErrAttachmentTooLarge
+	}
+	if err := enc.Close(); err != nil {
+		return "", "", err
+	}
+	return typ, sb.String(), nil
+}
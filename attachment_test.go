@@ -0,0 +1,102 @@
+package mandrill
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestNewAttachment(t *testing.T) {
+	a, err := NewAttachment("notes", strings.NewReader("plain text notes"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if a.Name != "notes" || !strings.HasPrefix(a.Type, "text/plain") {
+		t.Errorf("expected sniffed text attachment. Received: %+v", a)
+	}
+	if b, _ := base64.StdEncoding.DecodeString(a.Content); string(b) != "plain text notes" {
+		t.Errorf("unexpected content. Received: %q", b)
+	}
+}
+
+func TestNewAttachmentFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mandrill")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "report.pdf")
+	if err := ioutil.WriteFile(filename, []byte("%PDF-1.4 test"), 0600); err != nil {
+		t.Error(err)
+		return
+	}
+
+	a, err := NewAttachmentFromFile(filename)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if a.Name != "report.pdf" || a.Type != "application/pdf" || a.Content != base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 test")) {
+		t.Errorf("unexpected attachment. Received: %+v", a)
+	}
+}
+
+func TestNewImageFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/logo.png": {Data: testPNG},
+		"assets/banner":   {Data: testPNG},
+		"assets/doc.txt":  {Data: []byte("not an image")},
+	}
+	img, err := NewImageFromFS(fsys, "assets/logo.png")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if img.Name != "logo.png" || img.Type != "image/png" || img.Content != base64.StdEncoding.EncodeToString(testPNG) {
+		t.Errorf("unexpected image. Received: %+v", img)
+	}
+
+	img, err = NewImageFromFS(fsys, "assets/banner")
+	if err != nil || img.Type != "image/png" {
+		t.Errorf("expected sniffed png. Received: %+v, %v", img, err)
+	}
+
+	if _, err := NewImageFromFS(fsys, "assets/doc.txt"); err == nil {
+		t.Errorf("expected error for non-image")
+	}
+
+	a, err := NewAttachmentFromFS(fsys, "assets/doc.txt")
+	if err != nil || a.Name != "doc.txt" {
+		t.Errorf("expected attachment. Received: %+v, %v", a, err)
+	}
+}
+
+func TestAttachmentTooLarge(t *testing.T) {
+	max := WithMaxSize(4)
+	if _, err := NewAttachment("big.txt", strings.NewReader("12345"), max); !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Errorf("expected ErrAttachmentTooLarge. Received: %v", err)
+	}
+	fsys := fstest.MapFS{"big.png": {Data: testPNG}}
+	if _, err := NewImageFromFS(fsys, "big.png", max); !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Errorf("expected ErrAttachmentTooLarge. Received: %v", err)
+	}
+	msg := &Message{HTML: `<img src="big.png">`}
+	if err := msg.EmbedImages(fsys, max); !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Errorf("expected ErrAttachmentTooLarge. Received: %v", err)
+	}
+	if _, err := NewImageFromFS(fsys, "big.png"); err != nil {
+		t.Errorf("expected the default limit to apply to other calls. Received: %v", err)
+	}
+	if _, err := NewAttachment("small.txt", strings.NewReader("1234"), max); err != nil {
+		t.Errorf("expected content at the limit to be accepted. Received: %v", err)
+	}
+}
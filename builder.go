@@ -0,0 +1,293 @@
+package mandrill
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// MessageBuilder builds a Message, keeping its recipients, merge vars and metadata consistent.
+// Problems are collected as methods are called and returned together by Build
+//
+//	msg, err := mandrill.NewMessage().
+//		From("support@example.com", "Support").
+//		To("jane@example.com", "Jane Doe").
+//		Subject("Hello *|NAME|*").
+//		HTML("<p>Welcome</p>").
+//		RecipientVar("jane@example.com", "NAME", "Jane").
+//		Build()
+type MessageBuilder struct {
+	msg  Message
+	errs []error
+}
+
+// NewMessage returns an empty MessageBuilder
+func NewMessage() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// From sets the sender address and optional name
+func (b *MessageBuilder) From(email, name string) *MessageBuilder {
+	if err := checkAddress(email); err != nil {
+		b.errorf("from: %s", err)
+	}
+	b.msg.FromEmail = email
+	b.msg.FromName = name
+	return b
+}
+
+// To adds a "to" recipient
+func (b *MessageBuilder) To(email, name string) *MessageBuilder {
+	return b.recipient(email, name, RecipientTo)
+}
+
+// CC adds a "cc" recipient
+func (b *MessageBuilder) CC(email, name string) *MessageBuilder {
+	return b.recipient(email, name, RecipientCC)
+}
+
+// BCC adds a "bcc" recipient
+func (b *MessageBuilder) BCC(email, name string) *MessageBuilder {
+	return b.recipient(email, name, RecipientBCC)
+}
+
+func (b *MessageBuilder) recipient(email, name, typ string) *MessageBuilder {
+	if err := checkAddress(email); err != nil {
+		b.errorf("%s: %s", typ, err)
+		return b
+	}
+	if b.msg.hasRecipient(email) {
+		b.errorf("%s: duplicate recipient %q", typ, email)
+		return b
+	}
+	b.msg.To = append(b.msg.To, Recipient{Email: email, Name: name, Type: typ})
+	return b
+}
+
+// Subject sets the message subject
+func (b *MessageBuilder) Subject(subject string) *MessageBuilder {
+	b.msg.Subject = subject
+	return b
+}
+
+// HTML sets the html content
+func (b *MessageBuilder) HTML(html string) *MessageBuilder {
+	b.msg.HTML = html
+	return b
+}
+
+// Text sets the text content
+func (b *MessageBuilder) Text(text string) *MessageBuilder {
+	b.msg.Text = text
+	return b
+}
+
+// Header sets an extra header. Only Reply-To and X-* headers are accepted by the api
+func (b *MessageBuilder) Header(name, value string) *MessageBuilder {
+	if !strings.EqualFold(name, "Reply-To") && !strings.HasPrefix(strings.ToLower(name), "x-") {
+		b.errorf("header: %q is not allowed, only Reply-To and X-* headers are", name)
+		return b
+	}
+	if b.msg.Headers == nil {
+		b.msg.Headers = make(map[string]string)
+	}
+	b.msg.Headers[name] = value
+	return b
+}
+
+// Tag adds tags to the message
+func (b *MessageBuilder) Tag(tags ...string) *MessageBuilder {
+	b.msg.Tags = append(b.msg.Tags, tags...)
+	return b
+}
+
+// Subaccount sets the subaccount to send from
+func (b *MessageBuilder) Subaccount(id string) *MessageBuilder {
+	b.msg.SubAccount = id
+	return b
+}
+
+// MergeLanguage sets the merge tag language, MergeLanguageMailchimp or MergeLanguageHandlebars
+func (b *MessageBuilder) MergeLanguage(lang string) *MessageBuilder {
+	if lang != MergeLanguageMailchimp && lang != MergeLanguageHandlebars {
+		b.errorf("merge language: %q is not %q or %q", lang, MergeLanguageMailchimp, MergeLanguageHandlebars)
+		return b
+	}
+	b.msg.MergeLang = lang
+	return b
+}
+
+// Var sets a global merge var, replacing any previous value for name
+func (b *MessageBuilder) Var(name string, content interface{}) *MessageBuilder {
+	b.msg.Merge = true
+	b.msg.GlobalMergeVars = setMergeVar(b.msg.GlobalMergeVars, name, content)
+	return b
+}
+
+// RecipientVar sets a merge var for a single recipient, which must be added with To, CC or BCC
+func (b *MessageBuilder) RecipientVar(email, name string, content interface{}) *MessageBuilder {
+	b.msg.Merge = true
+	for i, rv := range b.msg.MergeVars {
+		if strings.EqualFold(rv.Recipient, email) {
+			b.msg.MergeVars[i].Vars = setMergeVar(rv.Vars, name, content)
+			return b
+		}
+	}
+	b.msg.MergeVars = append(b.msg.MergeVars, RecipientMergeVar{email, []MergeVar{{name, content}}})
+	return b
+}
+
+// Vars sets the global merge vars encoded from a struct or map, see MergeVars
+func (b *MessageBuilder) Vars(v interface{}) *MessageBuilder {
+	for _, mv := range b.mergeVars(v) {
+		b.Var(mv.Name, mv.Content)
+	}
+	return b
+}
+
+// RecipientVars sets the merge vars for a single recipient encoded from a struct or map, see MergeVars
+func (b *MessageBuilder) RecipientVars(email string, v interface{}) *MessageBuilder {
+	for _, mv := range b.mergeVars(v) {
+		b.RecipientVar(email, mv.Name, mv.Content)
+	}
+	return b
+}
+
+func (b *MessageBuilder) mergeVars(v interface{}) []MergeVar {
+	vars, err := MergeVars(v)
+	var me *MessageError
+	if errors.As(err, &me) {
+		b.errs = append(b.errs, me.Errs...)
+	} else if err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return vars
+}
+
+// Metadata sets a metadata value for the message
+func (b *MessageBuilder) Metadata(key, value string) *MessageBuilder {
+	if b.msg.Metadata == nil {
+		b.msg.Metadata = make(map[string]string)
+	}
+	b.msg.Metadata[key] = value
+	return b
+}
+
+// RecipientMetadata sets a metadata value for a single recipient, which must be added with To, CC or BCC
+func (b *MessageBuilder) RecipientMetadata(email, key, value string) *MessageBuilder {
+	for _, rm := range b.msg.RecipientMetadata {
+		if strings.EqualFold(rm.Recipient, email) {
+			rm.Values[key] = value
+			return b
+		}
+	}
+	b.msg.RecipientMetadata = append(b.msg.RecipientMetadata, RecipientMetadata{email, map[string]string{key: value}})
+	return b
+}
+
+// Attach adds an attachment, base64 encoding content
+func (b *MessageBuilder) Attach(name, mimeType string, content []byte) *MessageBuilder {
+	b.msg.Attachments = append(b.msg.Attachments, &Attachment{
+		Name:    name,
+		Type:    mimeType,
+		Content: base64.StdEncoding.EncodeToString(content),
+	})
+	return b
+}
+
+// Image adds an embedded image, base64 encoding content. Reference it in html as <img src="cid:name">
+func (b *MessageBuilder) Image(name, mimeType string, content []byte) *MessageBuilder {
+	b.msg.Images = append(b.msg.Images, &Image{
+		Name:    name,
+		Type:    mimeType,
+		Content: base64.StdEncoding.EncodeToString(content),
+	})
+	return b
+}
+
+// Build returns the message, or a *MessageError listing every problem found,
+// including those reported by Message.Validate
+func (b *MessageBuilder) Build() (*Message, error) {
+	errs := append([]error(nil), b.errs...)
+	if b.msg.FromEmail == "" {
+		errs = append(errs, fmt.Errorf("from: no sender address"))
+	}
+	if len(b.msg.To) == 0 {
+		errs = append(errs, fmt.Errorf("to: no recipients"))
+	}
+	if err := b.msg.Validate(); err != nil {
+		errs = append(errs, err.(*MessageError).Errs...)
+	}
+	if len(errs) > 0 {
+		return nil, &MessageError{errs}
+	}
+	return b.msg.clone(), nil
+}
+
+// clone returns a copy of msg that shares no slices or maps with it. Merge var contents are not copied
+func (msg *Message) clone() *Message {
+	c := *msg
+	c.To = append([]Recipient(nil), msg.To...)
+	c.Headers = copyStrings(msg.Headers)
+	c.GlobalMergeVars = append([]MergeVar(nil), msg.GlobalMergeVars...)
+	c.MergeVars = nil
+	for _, rv := range msg.MergeVars {
+		c.MergeVars = append(c.MergeVars, RecipientMergeVar{rv.Recipient, append([]MergeVar(nil), rv.Vars...)})
+	}
+	c.Tags = append([]string(nil), msg.Tags...)
+	c.GoogleAnalyticsDomain = append([]string(nil), msg.GoogleAnalyticsDomain...)
+	c.GoogleAnalyticsCampaign = append([]string(nil), msg.GoogleAnalyticsCampaign...)
+	c.Metadata = copyStrings(msg.Metadata)
+	c.RecipientMetadata = nil
+	for _, rm := range msg.RecipientMetadata {
+		c.RecipientMetadata = append(c.RecipientMetadata, RecipientMetadata{rm.Recipient, copyStrings(rm.Values)})
+	}
+	c.Attachments = nil
+	for _, a := range msg.Attachments {
+		a := *a
+		c.Attachments = append(c.Attachments, &a)
+	}
+	c.Images = nil
+	for _, img := range msg.Images {
+		img := *img
+		c.Images = append(c.Images, &img)
+	}
+	return &c
+}
+
+func copyStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func (b *MessageBuilder) errorf(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Errorf(format, args...))
+}
+
+// setMergeVar returns vars with name set to content
+func setMergeVar(vars []MergeVar, name string, content interface{}) []MergeVar {
+	for i, v := range vars {
+		if strings.EqualFold(v.Name, name) {
+			vars[i].Content = content
+			return vars
+		}
+	}
+	return append(vars, MergeVar{name, content})
+}
+
+// checkAddress returns an error if email is not a bare email address
+func checkAddress(email string) error {
+	a, err := mail.ParseAddress(email)
+	if err != nil || a.Address != email {
+		return fmt.Errorf("invalid address %q", email)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package mandrill
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMessageBuilder(t *testing.T) {
+	msg, err := NewMessage().
+		From(TestFromEmail, "Test Sender").
+		To("accept@test.mandrillapp.com", "Test Recipient").
+		CC("cc@example.com", "").
+		BCC("bcc@example.com", "").
+		Subject("Test Builder").
+		HTML("<p>Hello *|NAME|*</p>").
+		Var("COMPANY", "Example").
+		RecipientVar("accept@test.mandrillapp.com", "NAME", "Jane").
+		RecipientVar("accept@test.mandrillapp.com", "NAME", "Janet").
+		RecipientMetadata("cc@example.com", "user_id", "123").
+		MergeLanguage(MergeLanguageMailchimp).
+		Attach("test.txt", "text/plain", []byte("test attachment")).
+		Build()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(msg.To) != 3 || msg.To[1].Type != RecipientCC || msg.To[2].Type != RecipientBCC {
+		t.Errorf("unexpected recipients. Received: %+v", msg.To)
+	}
+	if !msg.Merge || len(msg.MergeVars) != 1 || len(msg.MergeVars[0].Vars) != 1 || msg.MergeVars[0].Vars[0].Content != "Janet" {
+		t.Errorf("expected one replaced recipient var. Received: %+v", msg.MergeVars)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Content != "dGVzdCBhdHRhY2htZW50" {
+		t.Errorf("expected base64 attachment. Received: %+v", msg.Attachments)
+	}
+
+	b, _ := json.Marshal(msg.RecipientMetadata)
+	if string(b) != `[{"rcpt":"cc@example.com","values":{"user_id":"123"}}]` {
+		t.Errorf("unexpected recipient metadata JSON. Received: %s", b)
+	}
+
+	m := NewMandrill(TestAPIKey)
+	if _, err := m.Messages().Send(msg, false, "", nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMessageBuilderReuse(t *testing.T) {
+	b := NewMessage().
+		From(TestFromEmail, "").
+		To("jane@example.com", "").
+		Header("X-Campaign", "a").
+		Metadata("batch", "1").
+		RecipientVar("jane@example.com", "NAME", "Jane").
+		RecipientMetadata("jane@example.com", "id", "1").
+		Attach("a.txt", "text/plain", []byte("a"))
+	first, err := b.Build()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	b.To("john@example.com", "").
+		Header("X-Campaign", "b").
+		Metadata("batch", "2").
+		RecipientVar("jane@example.com", "NAME", "Janet").
+		RecipientMetadata("jane@example.com", "id", "2").
+		Attach("b.txt", "text/plain", []byte("b"))
+	second, err := b.Build()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	second.Attachments[0].Name = "changed.txt"
+
+	if len(first.To) != 1 || first.Headers["X-Campaign"] != "a" || first.Metadata["batch"] != "1" ||
+		first.MergeVars[0].Vars[0].Content != "Jane" || first.RecipientMetadata[0].Values["id"] != "1" ||
+		len(first.Attachments) != 1 || first.Attachments[0].Name != "a.txt" {
+		t.Errorf("expected first message to be unchanged. Received: %+v", first)
+	}
+	if len(second.To) != 2 || second.Headers["X-Campaign"] != "b" || second.MergeVars[0].Vars[0].Content != "Janet" {
+		t.Errorf("unexpected second message. Received: %+v", second)
+	}
+}
+
+func TestMessageBuilderErrors(t *testing.T) {
+	_, err := NewMessage().
+		From("not an address", "").
+		To("jane@example.com", "").
+		To("jane@example.com", "").
+		RecipientVar("john@example.com", "NAME", "John").
+		RecipientMetadata("john@example.com", "user_id", "123").
+		MergeLanguage("liquid").
+		Header("Subject", "Not allowed").
+		Image("logo", "text/plain", nil).
+		Build()
+	var me *MessageError
+	if !errors.As(err, &me) {
+		t.Errorf("expected *MessageError. Received: %v", err)
+		return
+	}
+	if len(me.Errs) != 7 {
+		t.Errorf("expected 7 errors. Received: %d: %v", len(me.Errs), err)
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("expected error to match ErrValidation. Received: %v", err)
+	}
+	if !strings.Contains(err.Error(), `"john@example.com" is not a recipient`) {
+		t.Errorf("expected missing recipient error. Received: %v", err)
+	}
+
+	if _, err := NewMessage().Build(); err == nil {
+		t.Errorf("expected error for empty message")
+	}
+}
@@ -0,0 +1,269 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bulk sending defaults
+const (
+	DefaultBulkChunkSize   = 500
+	DefaultBulkConcurrency = 4
+)
+
+// BulkSender sends a message with many recipients as several smaller messages,
+// each with a chunk of the recipients and their merge vars and metadata
+type BulkSender struct {
+	// Sender sends each chunk, e.g. m.Messages() or an *SMTPSender
+	Sender MessageSender
+
+	// the maximum number of recipients per chunk, DefaultBulkChunkSize if zero
+	ChunkSize int
+
+	// the maximum number of chunks sent at once, DefaultBulkConcurrency if zero
+	Concurrency int
+
+	// Retry controls retrying of chunks that fail with a transient error. As with the
+	// client, sends are only retried if the connection could not be made, unless
+	// RetryNonIdempotent is set. Nil means no retries. If Sender is the Messages of a
+	// client with its own retry policy, the client retries each request and Retry is
+	// ignored, so attempts are not multiplied
+	Retry *RetryPolicy
+}
+
+// NewBulkSender returns a BulkSender sending through s with the default chunk size
+// and concurrency, retrying chunks according to DefaultRetryPolicy
+func NewBulkSender(s MessageSender) *BulkSender {
+	p := DefaultRetryPolicy
+	return &BulkSender{Sender: s, Retry: &p}
+}
+
+// BulkResult is the outcome of a bulk send
+type BulkResult struct {
+	// the outcome for each recipient, in the order of the message's To
+	Recipients []BulkRecipientResult
+
+	Summary BulkSummary
+}
+
+// BulkRecipientResult is the outcome of a bulk send for one recipient
+type BulkRecipientResult struct {
+	Recipient Recipient
+
+	// the sender's response, nil if the chunk failed or the sender returned none for the recipient
+	Response *SendResponse
+
+	// the error of the recipient's chunk, nil if it was sent
+	Err error
+}
+
+// BulkSummary counts recipients by status
+type BulkSummary struct {
+	Sent      int
+	Queued    int
+	Scheduled int
+	Rejected  int
+	Invalid   int
+
+	// recipients whose chunk failed after any retries
+	Failed int
+}
+
+func (s BulkSummary) String() string {
+	return fmt.Sprintf("sent %d, queued %d, scheduled %d, rejected %d, invalid %d, failed %d",
+		s.Sent, s.Queued, s.Scheduled, s.Rejected, s.Invalid, s.Failed)
+}
+
+func (b *BulkSender) Send(message *Message, async bool, ipPool string, sendAt *time.Time) (*BulkResult, error) {
+	return b.SendContext(context.Background(), message, async, ipPool, sendAt)
+}
+
+// SendContext is like Send but carries ctx through each chunk. The result is always returned;
+// the error is non-nil if any chunk failed, wrapping the first chunk error
+func (b *BulkSender) SendContext(ctx context.Context, message *Message, async bool, ipPool string, sendAt *time.Time) (*BulkResult, error) {
+	if message == nil {
+		return nil, errors.New("mandrill: no message to send")
+	}
+	chunks := message.Chunks(b.chunkSize())
+	res := &BulkResult{Recipients: make([]BulkRecipientResult, len(message.To))}
+	for i, r := range message.To {
+		res.Recipients[i].Recipient = r
+	}
+
+	// chunks hold consecutive recipients, so each starts where the previous one ended
+	type job struct {
+		start int
+		chunk *Message
+	}
+	var mu sync.Mutex
+	var errs []error
+	work := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < b.concurrency() && i < len(chunks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range work {
+				rr, err := b.sendChunk(ctx, j.chunk, async, ipPool, sendAt)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				}
+				res.record(j.start, len(j.chunk.To), rr, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	start := 0
+	for _, chunk := range chunks {
+		work <- job{start, chunk}
+		start += len(chunk.To)
+	}
+	close(work)
+	wg.Wait()
+
+	for _, r := range res.Recipients {
+		if r.Err != nil {
+			res.Summary.Failed++
+			continue
+		}
+		if r.Response == nil {
+			continue
+		}
+		switch r.Response.Status {
+		case "sent":
+			res.Summary.Sent++
+		case "queued":
+			res.Summary.Queued++
+		case "scheduled":
+			res.Summary.Scheduled++
+		case "rejected":
+			res.Summary.Rejected++
+		case "invalid":
+			res.Summary.Invalid++
+		}
+	}
+	if len(errs) > 0 {
+		return res, fmt.Errorf("mandrill: %d of %d chunks failed: %w", len(errs), len(chunks), errs[0])
+	}
+	return res, nil
+}
+
+// record stores the outcome of the chunk of n recipients starting at start. Each response
+// goes to the first recipient of the chunk with its email that has none yet, so repeated
+// addresses keep one response each
+func (res *BulkResult) record(start, n int, rr []SendResponse, err error) {
+	recipients := res.Recipients[start : start+n]
+	if err != nil {
+		for i := range recipients {
+			recipients[i].Err = err
+		}
+		return
+	}
+	for i := range rr {
+		for j := range recipients {
+			if recipients[j].Response == nil && strings.EqualFold(recipients[j].Recipient.Email, rr[i].Email) {
+				recipients[j].Response = &rr[i]
+				break
+			}
+		}
+	}
+}
+
+// sendChunk sends chunk, retrying transient failures
+func (b *BulkSender) sendChunk(ctx context.Context, chunk *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rr, err := b.Sender.SendContext(ctx, chunk, async, ipPool, sendAt)
+		if err == nil {
+			return rr, nil
+		}
+		if b.Retry == nil || b.clientRetries() || attempt >= b.Retry.MaxAttempts || !b.retryable(err) {
+			return nil, err
+		}
+		if err := sleepContext(ctx, b.Retry.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// clientRetries reports whether Sender already retries requests through its client's policy
+func (b *BulkSender) clientRetries() bool {
+	m, ok := b.Sender.(*Messages)
+	return ok && m.m.Retry != nil
+}
+
+// retryable reports whether a chunk that failed with err may be sent again
+func (b *BulkSender) retryable(err error) bool {
+	var ae *APIError
+	var de *DecodeError
+	var te *TransportError
+	var oe *net.OpError
+	status := 0
+	switch {
+	case errors.As(err, &ae):
+		status = ae.HTTPStatus
+	case errors.As(err, &de):
+		status = de.HTTPStatus
+	case errors.As(err, &te), errors.As(err, &oe):
+	default:
+		return false
+	}
+	return b.Retry.retryable("/messages/send.json", status, err)
+}
+
+func (b *BulkSender) chunkSize() int {
+	if b.ChunkSize > 0 {
+		return b.ChunkSize
+	}
+	return DefaultBulkChunkSize
+}
+
+func (b *BulkSender) concurrency() int {
+	if b.Concurrency > 0 {
+		return b.Concurrency
+	}
+	return DefaultBulkConcurrency
+}
+
+// Chunks splits msg into messages of at most size recipients. Each chunk is a copy of
+// msg with only the merge vars and recipient metadata of its own recipients
+func (msg *Message) Chunks(size int) []*Message {
+	if size <= 0 || len(msg.To) <= size {
+		return []*Message{msg}
+	}
+	var ret []*Message
+	for start := 0; start < len(msg.To); start += size {
+		end := start + size
+		if end > len(msg.To) {
+			end = len(msg.To)
+		}
+		chunk := *msg
+		chunk.To = msg.To[start:end:end]
+		in := make(map[string]bool, end-start)
+		for _, r := range chunk.To {
+			in[strings.ToLower(r.Email)] = true
+		}
+		chunk.MergeVars = nil
+		for _, rv := range msg.MergeVars {
+			if in[strings.ToLower(rv.Recipient)] {
+				chunk.MergeVars = append(chunk.MergeVars, rv)
+			}
+		}
+		chunk.RecipientMetadata = nil
+		for _, rm := range msg.RecipientMetadata {
+			if in[strings.ToLower(rm.Recipient)] {
+				chunk.RecipientMetadata = append(chunk.RecipientMetadata, rm)
+			}
+		}
+		ret = append(ret, &chunk)
+	}
+	return ret
+}
@@ -0,0 +1,170 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/mandrill/mandrilltest"
+)
+
+func testBulkMessage(n int) *Message {
+	msg := &Message{FromEmail: TestFromEmail, Subject: "Test Bulk"}
+	for i := 0; i < n; i++ {
+		email := fmt.Sprintf("bulk%d@example.com", i)
+		msg.To = append(msg.To, Recipient{Email: email})
+		msg.MergeVars = append(msg.MergeVars, RecipientMergeVar{email, []MergeVar{{"ID", i}}})
+		msg.RecipientMetadata = append(msg.RecipientMetadata, RecipientMetadata{email, map[string]string{"id": fmt.Sprint(i)}})
+	}
+	return msg
+}
+
+// responded counts the recipients of res with a response
+func responded(res *BulkResult) int {
+	n := 0
+	for _, r := range res.Recipients {
+		if r.Response != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMessageChunks(t *testing.T) {
+	msg := testBulkMessage(25)
+	chunks := msg.Chunks(10)
+	if len(chunks) != 3 || len(chunks[2].To) != 5 {
+		t.Errorf("expected chunks of 10, 10 and 5. Received: %d chunks", len(chunks))
+		return
+	}
+	for _, c := range chunks {
+		if len(c.MergeVars) != len(c.To) || len(c.RecipientMetadata) != len(c.To) {
+			t.Errorf("expected merge vars and metadata for each recipient. Received: %+v", c)
+		}
+		for i, r := range c.To {
+			if c.MergeVars[i].Recipient != r.Email || c.RecipientMetadata[i].Recipient != r.Email {
+				t.Errorf("expected merge vars and metadata for %s. Received: %+v", r.Email, c)
+			}
+		}
+	}
+	if len(msg.To) != 25 || len(msg.MergeVars) != 25 {
+		t.Errorf("expected original message to be unchanged")
+	}
+}
+
+func TestBulkSender(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+
+	msg := testBulkMessage(23)
+	msg.To = append(msg.To, Recipient{Email: "reject@test.mandrillapp.com"}, Recipient{Email: "invalid"})
+	b := &BulkSender{Sender: m.Messages(), ChunkSize: 5, Concurrency: 2}
+	res, err := b.Send(msg, false, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if responded(res) != 25 || res.Summary.Rejected != 1 || res.Summary.Invalid != 1 || res.Summary.Sent+res.Summary.Queued != 23 {
+		t.Errorf("unexpected result. Received: %s", res.Summary)
+	}
+
+	if n := len(s.Requests()); n != 5 {
+		t.Errorf("expected 5 requests. Received: %d", n)
+	}
+	for _, sm := range s.Messages() {
+		var vars struct {
+			MergeVars []RecipientMergeVar `json:"merge_vars"`
+		}
+		json.Unmarshal(sm.Message, &vars)
+		if len(vars.MergeVars) > 5 {
+			t.Errorf("expected chunk merge vars only. Received: %d", len(vars.MergeVars))
+			break
+		}
+		if sm.State != "rejected" && sm.State != "invalid" && sm.Metadata["id"] == "" {
+			t.Errorf("expected recipient metadata for %s", sm.Email)
+		}
+	}
+}
+
+func TestBulkSenderRetry(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	s.AddFault(mandrilltest.Fault{Path: "/messages/send.json", Times: 1, Status: 503})
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+	msg := testBulkMessage(10)
+
+	// sends are not retried once the request may have reached Mandrill
+	b := &BulkSender{Sender: m.Messages(), ChunkSize: 5, Concurrency: 1, Retry: &testRetryPolicy}
+	res, err := b.Send(msg, false, "", nil)
+	if err == nil || res.Summary.Failed != 5 || responded(res) != 5 {
+		t.Errorf("expected 1 failed chunk. Received: %s, %v", res.Summary, err)
+	}
+
+	s.AddFault(mandrilltest.Fault{Path: "/messages/send.json", Times: 1, Status: 503})
+	p := testRetryPolicy
+	p.RetryNonIdempotent = true
+	b.Retry = &p
+	res, err = b.Send(msg, false, "", nil)
+	if err != nil || res.Summary.Failed != 0 || responded(res) != 10 {
+		t.Errorf("expected retried chunk to succeed. Received: %s, %v", res.Summary, err)
+	}
+}
+
+func TestBulkSenderCancelled(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sendAt := time.Now().Add(time.Hour)
+	res, err := NewBulkSender(m.Messages()).SendContext(ctx, testBulkMessage(3), false, "", &sendAt)
+	if err == nil || res.Summary.Failed != 3 {
+		t.Errorf("expected all recipients to fail. Received: %+v, %v", res, err)
+	}
+}
+
+func TestBulkSenderDuplicates(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+
+	msg := testBulkMessage(3)
+	msg.To = append(msg.To, Recipient{Email: "bulk1@example.com", Type: RecipientCC}, Recipient{Email: "BULK0@example.com"})
+	res, err := (&BulkSender{Sender: m.Messages(), ChunkSize: 2}).Send(msg, false, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(res.Recipients) != 5 || responded(res) != 5 {
+		t.Errorf("expected a response for each of 5 recipients. Received: %+v", res.Recipients)
+		return
+	}
+	for i, r := range res.Recipients {
+		if r.Recipient != msg.To[i] || r.Response.Id == "" {
+			t.Errorf("expected result %d for %s. Received: %+v", i, msg.To[i].Email, r)
+		}
+	}
+	if res.Recipients[1].Response == res.Recipients[3].Response {
+		t.Errorf("expected repeated recipients to have their own responses")
+	}
+}
+
+func TestBulkSenderClientRetry(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	s.AddFault(mandrilltest.Fault{Path: "/messages/send.json", Times: 2, Status: 503})
+	p := testRetryPolicy
+	p.RetryNonIdempotent = true
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL), WithRetry(p))
+
+	// the client retries each request, so the bulk sender does not retry on top of it
+	b := &BulkSender{Sender: m.Messages(), Retry: &p}
+	res, err := b.Send(testBulkMessage(2), false, "", nil)
+	if err != nil || responded(res) != 2 {
+		t.Errorf("expected client retries to succeed. Received: %s, %v", res.Summary, err)
+	}
+	if n := len(s.Requests()); n != 3 {
+		t.Errorf("expected 3 requests. Received: %d", n)
+	}
+}
@@ -1,16 +1,168 @@
 package mandrill
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 )
 
+// Sentinel errors for each documented Mandrill error name. Use errors.Is to match an *APIError
+var (
+	ErrInvalidKey               = errors.New("mandrill: Invalid_Key")
+	ErrPaymentRequired          = errors.New("mandrill: PaymentRequired")
+	ErrValidation               = errors.New("mandrill: ValidationError")
+	ErrGeneral                  = errors.New("mandrill: GeneralError")
+	ErrUnknownSubaccount        = errors.New("mandrill: Unknown_Subaccount")
+	ErrUnknownTemplate          = errors.New("mandrill: Unknown_Template")
+	ErrInvalidTemplate          = errors.New("mandrill: Invalid_Template")
+	ErrUnknownMessage           = errors.New("mandrill: Unknown_Message")
+	ErrInvalidTagName           = errors.New("mandrill: Invalid_Tag_Name")
+	ErrInvalidReject            = errors.New("mandrill: Invalid_Reject")
+	ErrUnknownSender            = errors.New("mandrill: Unknown_Sender")
+	ErrUnknownURL               = errors.New("mandrill: Unknown_Url")
+	ErrUnknownTrackingDomain    = errors.New("mandrill: Unknown_TrackingDomain")
+	ErrUnknownWebhook           = errors.New("mandrill: Unknown_Webhook")
+	ErrUnknownInboundDomain     = errors.New("mandrill: Unknown_InboundDomain")
+	ErrUnknownInboundRoute      = errors.New("mandrill: Unknown_InboundRoute")
+	ErrUnknownExport            = errors.New("mandrill: Unknown_Export")
+	ErrIPProvisionLimit         = errors.New("mandrill: IP_ProvisionLimit")
+	ErrUnknownPool              = errors.New("mandrill: Unknown_Pool")
+	ErrNoSendingHistory         = errors.New("mandrill: NoSendingHistory")
+	ErrPoorReputation           = errors.New("mandrill: PoorReputation")
+	ErrUnknownIP                = errors.New("mandrill: Unknown_IP")
+	ErrInvalidEmptyDefaultPool  = errors.New("mandrill: Invalid_EmptyDefaultPool")
+	ErrInvalidDeleteDefaultPool = errors.New("mandrill: Invalid_DeleteDefaultPool")
+	ErrInvalidDeleteNonEmpty    = errors.New("mandrill: Invalid_DeleteNonEmptyPool")
+	ErrInvalidCustomDNS         = errors.New("mandrill: Invalid_CustomDNS")
+	ErrInvalidCustomDNSPending  = errors.New("mandrill: Invalid_CustomDNSPending")
+	ErrMetadataFieldLimit       = errors.New("mandrill: Metadata_FieldLimit")
+	ErrUnknownMetadataField     = errors.New("mandrill: Unknown_MetadataField")
+)
+
+// apiErrors maps APIError.Name to its sentinel error
+var apiErrors = map[string]error{
+	"Invalid_Key":                ErrInvalidKey,
+	"PaymentRequired":            ErrPaymentRequired,
+	"ValidationError":            ErrValidation,
+	"GeneralError":               ErrGeneral,
+	"Unknown_Subaccount":         ErrUnknownSubaccount,
+	"Unknown_Template":           ErrUnknownTemplate,
+	"Invalid_Template":           ErrInvalidTemplate,
+	"Unknown_Message":            ErrUnknownMessage,
+	"Invalid_Tag_Name":           ErrInvalidTagName,
+	"Invalid_Reject":             ErrInvalidReject,
+	"Unknown_Sender":             ErrUnknownSender,
+	"Unknown_Url":                ErrUnknownURL,
+	"Unknown_TrackingDomain":     ErrUnknownTrackingDomain,
+	"Unknown_Webhook":            ErrUnknownWebhook,
+	"Unknown_InboundDomain":      ErrUnknownInboundDomain,
+	"Unknown_InboundRoute":       ErrUnknownInboundRoute,
+	"Unknown_Export":             ErrUnknownExport,
+	"IP_ProvisionLimit":          ErrIPProvisionLimit,
+	"Unknown_Pool":               ErrUnknownPool,
+	"NoSendingHistory":           ErrNoSendingHistory,
+	"PoorReputation":             ErrPoorReputation,
+	"Unknown_IP":                 ErrUnknownIP,
+	"Invalid_EmptyDefaultPool":   ErrInvalidEmptyDefaultPool,
+	"Invalid_DeleteDefaultPool":  ErrInvalidDeleteDefaultPool,
+	"Invalid_DeleteNonEmptyPool": ErrInvalidDeleteNonEmpty,
+	"Invalid_CustomDNS":          ErrInvalidCustomDNS,
+	"Invalid_CustomDNSPending":   ErrInvalidCustomDNSPending,
+	"Metadata_FieldLimit":        ErrMetadataFieldLimit,
+	"Unknown_MetadataField":      ErrUnknownMetadataField,
+}
+
+// APIError is an error response returned by the api
 type APIError struct {
 	Status  string
 	Code    int
 	Name    string
 	Message string
+
+	// the HTTP status code of the response
+	HTTPStatus int `json:"-"`
+
+	// the api path that was called, e.g. "/messages/send.json"
+	Path string `json:"-"`
+
+	// the raw response body
+	Body []byte `json:"-"`
 }
 
 func (a *APIError) Error() string {
 	return fmt.Sprintf("%s (%d). %s. %s", a.Status, a.Code, a.Name, a.Message)
 }
+
+// Is reports whether target is the sentinel error for a.Name
+func (a *APIError) Is(target error) bool {
+	sentinel, ok := apiErrors[a.Name]
+	return ok && sentinel == target
+}
+
+// TransportError is returned when a request could not be sent or its response could not be read.
+// Context cancellation and deadlines are returned as the context's error instead
+type TransportError struct {
+	// the api path that was called
+	Path string
+
+	Err error
+}
+
+func (t *TransportError) Error() string {
+	return fmt.Sprintf("%s: %s", t.Path, t.Err)
+}
+
+func (t *TransportError) Unwrap() error {
+	return t.Err
+}
+
+// DecodeError is returned when a response cannot be decoded: an error response that is not a
+// valid Mandrill error, such as an HTML page from a proxy, or a successful response that does
+// not match the expected result
+type DecodeError struct {
+	// the api path that was called
+	Path string
+
+	// the HTTP status code of the response
+	HTTPStatus int
+
+	// the raw response body
+	Body []byte
+
+	Err error
+}
+
+func (d *DecodeError) Error() string {
+	if d.HTTPStatus == http.StatusOK {
+		return fmt.Sprintf("%s: failed to decode response: %s", d.Path, d.Err)
+	}
+	return fmt.Sprintf("%s: failed to interpret api error (HTTP %d): %s", d.Path, d.HTTPStatus, d.Err)
+}
+
+func (d *DecodeError) Unwrap() error {
+	return d.Err
+}
+
+// MessageError is returned when a message fails client-side checks, listing every problem found.
+// It matches ErrValidation, as the api would reject the message
+type MessageError struct {
+	Errs []error
+}
+
+func (e *MessageError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return "mandrill: invalid message: " + strings.Join(msgs, "; ")
+}
+
+// Is reports whether target is ErrValidation
+func (e *MessageError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *MessageError) Unwrap() []error {
+	return e.Errs
+}
@@ -1,6 +1,12 @@
 package mandrill
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -14,7 +20,7 @@ func TestErrInvalidKey(t *testing.T) {
 
 func TestErrValidation(t *testing.T) {
 	m := NewMandrill(TestAPIKey)
-	_, err := m.execute("/users/ping.json", struct {
+	_, err := m.execute(context.Background(), "/users/ping.json", struct {
 		BadKey string `json:"bad-key"`
 	}{"bad-key"})
 	if ae, ok := err.(*APIError); !ok || ae.Name != "ValidationError" {
@@ -115,3 +121,73 @@ func TestErrUnknownMetadataField(t *testing.T) {
 		t.Errorf("expected unknown metadata field name. Received %s", err)
 	}
 }
+
+func TestErrIs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error","code":12,"name":"Unknown_Subaccount","message":"No subaccount exists with the id 'test'"}`))
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL))
+	_, err := m.Subaccounts().Info("test")
+	if !errors.Is(err, ErrUnknownSubaccount) || errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected unknown subaccount error. Received: %s", err)
+	}
+
+	var ae *APIError
+	if !errors.As(err, &ae) || ae.HTTPStatus != http.StatusInternalServerError ||
+		ae.Path != "/subaccounts/info.json" || len(ae.Body) == 0 {
+		t.Errorf("expected api error with status, path and body. Received: %+v", ae)
+	}
+}
+
+func TestErrDecode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>bad gateway</html>", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL))
+	_, err := m.Users().Ping()
+	var de *DecodeError
+	if !errors.As(err, &de) || de.HTTPStatus != http.StatusBadGateway || !strings.Contains(string(de.Body), "bad gateway") {
+		t.Errorf("expected decode error. Received: %s", err)
+	}
+}
+
+func TestErrDecodeResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"email": "jane@example.com", "status": `))
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL))
+	_, err := m.Messages().Send(&Message{}, false, "", nil)
+	var de *DecodeError
+	if !errors.As(err, &de) || de.HTTPStatus != http.StatusOK || de.Path != "/messages/send.json" || len(de.Body) == 0 {
+		t.Errorf("expected decode error. Received: %v", err)
+	}
+
+	_, err = m.Users().Info()
+	if !errors.As(err, &de) || de.Path != "/users/info.json" {
+		t.Errorf("expected decode error. Received: %v", err)
+	}
+}
+
+func TestErrTransport(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL("http://"+addr))
+	_, err = m.Users().Ping()
+	var te *TransportError
+	if !errors.As(err, &te) || te.Path != "/users/ping.json" {
+		t.Errorf("expected transport error. Received: %s", err)
+	}
+}
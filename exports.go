@@ -1,25 +1,30 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Exports struct {
 	m *Mandrill
 }
 
-func (e *Exports) Info(id string) (exportsResponse, error) {
-	var ret exportsResponse
+func (e *Exports) Info(id string) (ExportsResponse, error) {
+	return e.InfoContext(context.Background(), id)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (e *Exports) InfoContext(ctx context.Context, id string) (ExportsResponse, error) {
+	var ret ExportsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     string `json:"id"`
 	}{e.m.APIKey, id}
-	body, err := e.m.execute("/exports/info.json", data)
+	body, err := e.m.execute(ctx, "/exports/info.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/exports/info.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -27,7 +32,7 @@ func (e *Exports) Info(id string) (exportsResponse, error) {
 	return ret, nil
 }
 
-type exportsResponse struct {
+type ExportsResponse struct {
 	Id         string `json:"id"`
 	CreatedAt  string `json:"created_at"`
 	Type       string `json:"type"`
@@ -36,15 +41,20 @@ type exportsResponse struct {
 	ResultURL  string `json:"result_url"`
 }
 
-func (e *Exports) List() ([]exportsResponse, error) {
-	var ret []exportsResponse
+func (e *Exports) List() ([]ExportsResponse, error) {
+	return e.ListContext(context.Background())
+}
+
+// ListContext is like List but carries ctx through the request
+func (e *Exports) ListContext(ctx context.Context) ([]ExportsResponse, error) {
+	var ret []ExportsResponse
 	data := simpleRequest{e.m.APIKey}
-	body, err := e.m.execute("/exports/list.json", data)
+	body, err := e.m.execute(ctx, "/exports/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/exports/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -52,18 +62,23 @@ func (e *Exports) List() ([]exportsResponse, error) {
 	return ret, nil
 }
 
-func (e *Exports) Rejects(notifyEmail string) (exportsResponse, error) {
-	var ret exportsResponse
+func (e *Exports) Rejects(notifyEmail string) (ExportsResponse, error) {
+	return e.RejectsContext(context.Background(), notifyEmail)
+}
+
+// RejectsContext is like Rejects but carries ctx through the request
+func (e *Exports) RejectsContext(ctx context.Context, notifyEmail string) (ExportsResponse, error) {
+	var ret ExportsResponse
 	data := struct {
 		APIKey      string `json:"key"`
 		NotifyEmail string `json:"notify_email,omitempty"`
 	}{e.m.APIKey, notifyEmail}
-	body, err := e.m.execute("/exports/rejects.json", data)
+	body, err := e.m.execute(ctx, "/exports/rejects.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/exports/rejects.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -71,18 +86,23 @@ func (e *Exports) Rejects(notifyEmail string) (exportsResponse, error) {
 	return ret, nil
 }
 
-func (e *Exports) Whitelist(notifyEmail string) (exportsResponse, error) {
-	var ret exportsResponse
+func (e *Exports) Whitelist(notifyEmail string) (ExportsResponse, error) {
+	return e.WhitelistContext(context.Background(), notifyEmail)
+}
+
+// WhitelistContext is like Whitelist but carries ctx through the request
+func (e *Exports) WhitelistContext(ctx context.Context, notifyEmail string) (ExportsResponse, error) {
+	var ret ExportsResponse
 	data := struct {
 		APIKey      string `json:"key"`
 		NotifyEmail string `json:"notify_email,omitempty"`
 	}{e.m.APIKey, notifyEmail}
-	body, err := e.m.execute("/exports/whitelist.json", data)
+	body, err := e.m.execute(ctx, "/exports/whitelist.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/exports/whitelist.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -118,19 +138,24 @@ type ExportActivityRequest struct {
 // activity view. It includes the following fields: Date, Email Address, Sender, Subject, Status, Tags,
 // Opens, Clicks, Bounce Detail. If you have configured any custom metadata fields, they will be included
 // in the exported data.
-func (e *Exports) Activity(request *ExportActivityRequest) (exportsResponse, error) {
-	var ret exportsResponse
+func (e *Exports) Activity(request *ExportActivityRequest) (ExportsResponse, error) {
+	return e.ActivityContext(context.Background(), request)
+}
+
+// ActivityContext is like Activity but carries ctx through the request
+func (e *Exports) ActivityContext(ctx context.Context, request *ExportActivityRequest) (ExportsResponse, error) {
+	var ret ExportsResponse
 	type fakeRequest ExportActivityRequest
 	data := struct {
 		APIKey string `json:"key"`
 		fakeRequest
 	}{e.m.APIKey, fakeRequest(*request)}
-	body, err := e.m.execute("/exports/activity.json", data)
+	body, err := e.m.execute(ctx, "/exports/activity.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/exports/activity.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
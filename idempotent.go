@@ -0,0 +1,261 @@
+package mandrill
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyMetadataKey is the metadata field holding a message's idempotency key
+// when IdempotentSender.MetadataKey is empty
+const DefaultIdempotencyMetadataKey = "idempotency_key"
+
+// ErrInvalidIdempotencyKey is returned for keys that are empty, longer than 100 characters
+// or contain characters other than letters, digits, '-', '_' and '.'
+var ErrInvalidIdempotencyKey = errors.New("mandrill: invalid idempotency key")
+
+var idempotencyKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+
+// IdempotencyRecord is the state of a send with an idempotency key
+type IdempotencyRecord struct {
+	Key string `json:"key"`
+
+	// when the send was first attempted
+	CreatedAt time.Time `json:"created_at"`
+
+	// the responses of the send, nil if it was attempted but its outcome is unknown
+	Responses []SendResponse `json:"responses"`
+}
+
+// IdempotencyStore records sends by idempotency key. Implementations must be safe for
+// concurrent use
+type IdempotencyStore interface {
+	// Get returns the record for key, or nil if there is none
+	Get(key string) (*IdempotencyRecord, error)
+
+	// Put creates or replaces the record for r.Key
+	Put(r *IdempotencyRecord) error
+}
+
+// IdempotentSender sends each message at most once per idempotency key. Before sending,
+// the key is recorded in the store and added to the message metadata. A send with a key
+// whose responses were recorded returns them without sending. A send with a key whose
+// outcome is unknown, e.g. after a crash, first searches Mandrill for messages with the
+// key in their metadata, and only sends if none are found.
+//
+// Mandrill only indexes messages once they are processed, so a message sent moments
+// before a crash may not be found by the search
+type IdempotentSender struct {
+	// Messages sends messages and searches for earlier sends
+	Messages MessagesAPI
+
+	Store IdempotencyStore
+
+	// the metadata field holding the idempotency key, DefaultIdempotencyMetadataKey if empty.
+	// Add it as a searchable metadata field, see Metadata.Add, so that sends can be checked
+	MetadataKey string
+
+	mu   sync.Mutex
+	busy map[string]chan struct{}
+}
+
+// NewIdempotentSender returns an IdempotentSender sending through messages
+func NewIdempotentSender(messages MessagesAPI, store IdempotencyStore) *IdempotentSender {
+	return &IdempotentSender{Messages: messages, Store: store}
+}
+
+func (s *IdempotentSender) Send(key string, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	return s.SendContext(context.Background(), key, message, async, ipPool, sendAt)
+}
+
+// SendContext is like Send but carries ctx through the requests. Sends with the same
+// key are run one at a time
+func (s *IdempotentSender) SendContext(ctx context.Context, key string, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	if !idempotencyKeyRegexp.MatchString(key) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIdempotencyKey, key)
+	}
+	if message == nil {
+		return nil, errors.New("mandrill: no message to send")
+	}
+	if err := s.lock(ctx, key); err != nil {
+		return nil, err
+	}
+	defer s.unlock(key)
+
+	rec, err := s.Store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil && rec.Responses != nil {
+		return rec.Responses, nil
+	}
+	if rec != nil {
+		rr, err := s.search(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+		if len(rr) > 0 {
+			rec.Responses = rr
+			return rr, s.Store.Put(rec)
+		}
+	} else {
+		rec = &IdempotencyRecord{Key: key, CreatedAt: time.Now()}
+		if err := s.Store.Put(rec); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := *message
+	msg.Metadata = make(map[string]string, len(message.Metadata)+1)
+	for k, v := range message.Metadata {
+		msg.Metadata[k] = v
+	}
+	msg.Metadata[s.metadataKey()] = key
+	rr, err := s.Messages.SendContext(ctx, &msg, async, ipPool, sendAt)
+	if err != nil {
+		return nil, err
+	}
+	if rr == nil {
+		rr = []SendResponse{}
+	}
+	rec.Responses = rr
+	return rr, s.Store.Put(rec)
+}
+
+// search returns responses for the messages sent with rec's key, if any
+func (s *IdempotentSender) search(ctx context.Context, rec *IdempotencyRecord) ([]SendResponse, error) {
+	found, err := s.Messages.SearchContext(ctx, &MessagesSearchRequest{
+		Query:    fmt.Sprintf("u_%s:%s", s.metadataKey(), rec.Key),
+		DateFrom: rec.CreatedAt.AddDate(0, 0, -1),
+		Limit:    1000,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ret []SendResponse
+	for _, info := range found {
+		// the search matches on substrings, so the key is checked exactly
+		if info.Metadata[s.metadataKey()] != rec.Key {
+			continue
+		}
+		status := info.State
+		switch status {
+		case "queued", "scheduled", "rejected", "invalid":
+		default:
+			// bounces, deferrals and the like happen after a message is sent
+			status = "sent"
+		}
+		ret = append(ret, SendResponse{Email: info.Email, Status: status, Id: info.Id})
+	}
+	return ret, nil
+}
+
+func (s *IdempotentSender) metadataKey() string {
+	if s.MetadataKey != "" {
+		return s.MetadataKey
+	}
+	return DefaultIdempotencyMetadataKey
+}
+
+// lock waits until no other send with key is running
+func (s *IdempotentSender) lock(ctx context.Context, key string) error {
+	for {
+		s.mu.Lock()
+		if s.busy == nil {
+			s.busy = make(map[string]chan struct{})
+		}
+		wait, ok := s.busy[key]
+		if !ok {
+			s.busy[key] = make(chan struct{})
+			s.mu.Unlock()
+			return nil
+		}
+		s.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *IdempotentSender) unlock(key string) {
+	s.mu.Lock()
+	close(s.busy[key])
+	delete(s.busy, key)
+	s.mu.Unlock()
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore held in memory, which does not survive
+// a restart
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+func (m *MemoryIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (m *MemoryIdempotencyStore) Put(r *IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.records == nil {
+		m.records = make(map[string]IdempotencyRecord)
+	}
+	m.records[r.Key] = *r
+	return nil
+}
+
+// FileIdempotencyStore is an IdempotencyStore keeping each record in a JSON file in a directory
+type FileIdempotencyStore struct {
+	dir string
+}
+
+// NewFileIdempotencyStore returns a FileIdempotencyStore in dir, creating it if needed
+func NewFileIdempotencyStore(dir string) (*FileIdempotencyStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileIdempotencyStore{dir: dir}, nil
+}
+
+func (f *FileIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	b, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r IdempotencyRecord
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("mandrill: reading idempotency record %q: %s", key, err)
+	}
+	return &r, nil
+}
+
+func (f *FileIdempotencyStore) Put(r *IdempotencyRecord) error {
+	return writeFileAtomic(f.path(r.Key), r)
+}
+
+// path returns the file for key, named by its hash so that any key is a valid file name
+func (f *FileIdempotencyStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,142 @@
+package mandrill
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/jimtsao/mandrill/mandrilltest"
+)
+
+func testIdempotentMessage() *Message {
+	return &Message{FromEmail: TestFromEmail, Subject: "Test Idempotent", To: []Recipient{{Email: "once@example.com"}},
+		Metadata: map[string]string{"order": "42"}}
+}
+
+func TestIdempotentSender(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+	is := NewIdempotentSender(m.Messages(), &MemoryIdempotencyStore{})
+
+	msg := testIdempotentMessage()
+	first, err := is.Send("order-42", msg, false, "", nil)
+	if err != nil || len(first) != 1 {
+		t.Errorf("expected 1 response. Received: %+v, %v", first, err)
+		return
+	}
+	second, err := is.Send("order-42", msg, false, "", nil)
+	if err != nil || len(second) != 1 || second[0].Id != first[0].Id {
+		t.Errorf("expected cached response %s. Received: %+v, %v", first[0].Id, second, err)
+	}
+	if n := len(s.Messages()); n != 1 {
+		t.Errorf("expected 1 message sent. Received: %d", n)
+	}
+	if sm := s.Messages()[0]; sm.Metadata[DefaultIdempotencyMetadataKey] != "order-42" || sm.Metadata["order"] != "42" {
+		t.Errorf("expected idempotency key in metadata. Received: %v", sm.Metadata)
+	}
+	if len(msg.Metadata) != 1 {
+		t.Errorf("expected message metadata to be unchanged. Received: %v", msg.Metadata)
+	}
+
+	if _, err := is.Send("order 42/", msg, false, "", nil); !errors.Is(err, ErrInvalidIdempotencyKey) {
+		t.Errorf("expected ErrInvalidIdempotencyKey. Received: %v", err)
+	}
+}
+
+func TestIdempotentSenderRecovery(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+	dir, _ := ioutil.TempDir("", "idempotency")
+	defer os.RemoveAll(dir)
+	store, err := NewFileIdempotencyStore(dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	is := NewIdempotentSender(m.Messages(), store)
+
+	// the message was sent, but the process stopped before the responses were recorded
+	rr, err := is.Send("order-43", testIdempotentMessage(), false, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rec, _ := store.Get("order-43")
+	rec.Responses = nil
+	store.Put(rec)
+
+	replay, err := NewIdempotentSender(m.Messages(), store).Send("order-43", testIdempotentMessage(), false, "", nil)
+	if err != nil || len(replay) != 1 || replay[0].Id != rr[0].Id || replay[0].Status != "sent" {
+		t.Errorf("expected response found by search. Received: %+v, %v", replay, err)
+	}
+	if n := len(s.Messages()); n != 1 {
+		t.Errorf("expected 1 message sent. Received: %d", n)
+	}
+	if rec, _ := store.Get("order-43"); rec == nil || len(rec.Responses) != 1 {
+		t.Errorf("expected recovered responses to be recorded. Received: %+v", rec)
+	}
+
+	// a send whose outcome is unknown and cannot be found is sent again
+	store.Put(&IdempotencyRecord{Key: "order-44", CreatedAt: rec.CreatedAt})
+	if _, err := is.Send("order-44", testIdempotentMessage(), false, "", nil); err != nil {
+		t.Error(err)
+	}
+	if n := len(s.Messages()); n != 2 {
+		t.Errorf("expected 2 messages sent. Received: %d", n)
+	}
+}
+
+func TestIdempotentSenderConcurrent(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+	is := NewIdempotentSender(m.Messages(), &MemoryIdempotencyStore{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := is.Send("order-45", testIdempotentMessage(), false, "", nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(s.Messages()); n != 1 {
+		t.Errorf("expected 1 message sent. Received: %d", n)
+	}
+}
+
+func TestIdempotentSenderMetadataKey(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+	store := &MemoryIdempotencyStore{}
+	is := NewIdempotentSender(m.Messages(), store)
+	is.MetadataKey = "send_key"
+
+	rr, err := is.Send("order-46", testIdempotentMessage(), false, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if sm := s.Messages()[0]; sm.Metadata["send_key"] != "order-46" || sm.Metadata[DefaultIdempotencyMetadataKey] != "" {
+		t.Errorf("expected idempotency key in send_key. Received: %v", sm.Metadata)
+	}
+
+	rec, _ := store.Get("order-46")
+	rec.Responses = nil
+	store.Put(rec)
+	replay, err := is.Send("order-46", testIdempotentMessage(), false, "", nil)
+	if err != nil || len(replay) != 1 || replay[0].Id != rr[0].Id {
+		t.Errorf("expected response found by search on send_key. Received: %+v, %v", replay, err)
+	}
+	if n := len(s.Messages()); n != 1 {
+		t.Errorf("expected 1 message sent. Received: %d", n)
+	}
+}
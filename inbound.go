@@ -1,28 +1,33 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Inbound struct {
 	m *Mandrill
 }
 
-type inboundDomainResponse struct {
+type InboundDomainResponse struct {
 	Domain    string `json:"domain"`
 	CreatedAt string `json:"created_at"`
 	ValidMX   bool   `json:"valid_mx"`
 }
 
-func (i *Inbound) Domains() ([]inboundDomainResponse, error) {
-	var ret []inboundDomainResponse
+func (i *Inbound) Domains() ([]InboundDomainResponse, error) {
+	return i.DomainsContext(context.Background())
+}
+
+// DomainsContext is like Domains but carries ctx through the request
+func (i *Inbound) DomainsContext(ctx context.Context) ([]InboundDomainResponse, error) {
+	var ret []InboundDomainResponse
 	data := simpleRequest{i.m.APIKey}
-	body, err := i.m.execute("/inbound/domains.json", data)
+	body, err := i.m.execute(ctx, "/inbound/domains.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/domains.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -30,18 +35,23 @@ func (i *Inbound) Domains() ([]inboundDomainResponse, error) {
 	return ret, nil
 }
 
-func (i *Inbound) AddDomain(domain string) (inboundDomainResponse, error) {
-	var ret inboundDomainResponse
+func (i *Inbound) AddDomain(domain string) (InboundDomainResponse, error) {
+	return i.AddDomainContext(context.Background(), domain)
+}
+
+// AddDomainContext is like AddDomain but carries ctx through the request
+func (i *Inbound) AddDomainContext(ctx context.Context, domain string) (InboundDomainResponse, error) {
+	var ret InboundDomainResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{i.m.APIKey, domain}
-	body, err := i.m.execute("/inbound/add-domain.json", data)
+	body, err := i.m.execute(ctx, "/inbound/add-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/add-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -49,18 +59,23 @@ func (i *Inbound) AddDomain(domain string) (inboundDomainResponse, error) {
 	return ret, nil
 }
 
-func (i *Inbound) CheckDomain(domain string) (inboundDomainResponse, error) {
-	var ret inboundDomainResponse
+func (i *Inbound) CheckDomain(domain string) (InboundDomainResponse, error) {
+	return i.CheckDomainContext(context.Background(), domain)
+}
+
+// CheckDomainContext is like CheckDomain but carries ctx through the request
+func (i *Inbound) CheckDomainContext(ctx context.Context, domain string) (InboundDomainResponse, error) {
+	var ret InboundDomainResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{i.m.APIKey, domain}
-	body, err := i.m.execute("/inbound/check-domain.json", data)
+	body, err := i.m.execute(ctx, "/inbound/check-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/check-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -68,18 +83,23 @@ func (i *Inbound) CheckDomain(domain string) (inboundDomainResponse, error) {
 	return ret, nil
 }
 
-func (i *Inbound) DeleteDomain(domain string) (inboundDomainResponse, error) {
-	var ret inboundDomainResponse
+func (i *Inbound) DeleteDomain(domain string) (InboundDomainResponse, error) {
+	return i.DeleteDomainContext(context.Background(), domain)
+}
+
+// DeleteDomainContext is like DeleteDomain but carries ctx through the request
+func (i *Inbound) DeleteDomainContext(ctx context.Context, domain string) (InboundDomainResponse, error) {
+	var ret InboundDomainResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{i.m.APIKey, domain}
-	body, err := i.m.execute("/inbound/delete-domain.json", data)
+	body, err := i.m.execute(ctx, "/inbound/delete-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/delete-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -87,24 +107,29 @@ func (i *Inbound) DeleteDomain(domain string) (inboundDomainResponse, error) {
 	return ret, nil
 }
 
-type inboundRouteResponse struct {
+type InboundRouteResponse struct {
 	Id      string `json:"id"`
 	Pattern string `json:"pattern"`
 	URL     string `json:"url"`
 }
 
-func (i *Inbound) Routes(domain string) ([]inboundRouteResponse, error) {
-	var ret []inboundRouteResponse
+func (i *Inbound) Routes(domain string) ([]InboundRouteResponse, error) {
+	return i.RoutesContext(context.Background(), domain)
+}
+
+// RoutesContext is like Routes but carries ctx through the request
+func (i *Inbound) RoutesContext(ctx context.Context, domain string) ([]InboundRouteResponse, error) {
+	var ret []InboundRouteResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{i.m.APIKey, domain}
-	body, err := i.m.execute("/inbound/routes.json", data)
+	body, err := i.m.execute(ctx, "/inbound/routes.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/routes.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -112,20 +137,25 @@ func (i *Inbound) Routes(domain string) ([]inboundRouteResponse, error) {
 	return ret, nil
 }
 
-func (i *Inbound) AddRoute(domain string, pattern string, url string) (inboundRouteResponse, error) {
-	var ret inboundRouteResponse
+func (i *Inbound) AddRoute(domain string, pattern string, url string) (InboundRouteResponse, error) {
+	return i.AddRouteContext(context.Background(), domain, pattern, url)
+}
+
+// AddRouteContext is like AddRoute but carries ctx through the request
+func (i *Inbound) AddRouteContext(ctx context.Context, domain string, pattern string, url string) (InboundRouteResponse, error) {
+	var ret InboundRouteResponse
 	data := struct {
 		APIKey  string `json:"key"`
 		Domain  string `json:"domain"`
 		Pattern string `json:"pattern"`
 		URL     string `json:"url"`
 	}{i.m.APIKey, domain, pattern, url}
-	body, err := i.m.execute("/inbound/add-route.json", data)
+	body, err := i.m.execute(ctx, "/inbound/add-route.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/add-route.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -133,20 +163,25 @@ func (i *Inbound) AddRoute(domain string, pattern string, url string) (inboundRo
 	return ret, nil
 }
 
-func (i *Inbound) UpdateRoute(id string, pattern string, url string) (inboundRouteResponse, error) {
-	var ret inboundRouteResponse
+func (i *Inbound) UpdateRoute(id string, pattern string, url string) (InboundRouteResponse, error) {
+	return i.UpdateRouteContext(context.Background(), id, pattern, url)
+}
+
+// UpdateRouteContext is like UpdateRoute but carries ctx through the request
+func (i *Inbound) UpdateRouteContext(ctx context.Context, id string, pattern string, url string) (InboundRouteResponse, error) {
+	var ret InboundRouteResponse
 	data := struct {
 		APIKey  string `json:"key"`
 		Id      string `json:"id"`
 		Pattern string `json:"pattern,omitempty"`
 		URL     string `json:"url,omitempty"`
 	}{i.m.APIKey, id, pattern, url}
-	body, err := i.m.execute("/inbound/update-route.json", data)
+	body, err := i.m.execute(ctx, "/inbound/update-route.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/update-route.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -154,18 +189,23 @@ func (i *Inbound) UpdateRoute(id string, pattern string, url string) (inboundRou
 	return ret, nil
 }
 
-func (i *Inbound) DeleteRoute(id string) (inboundRouteResponse, error) {
-	var ret inboundRouteResponse
+func (i *Inbound) DeleteRoute(id string) (InboundRouteResponse, error) {
+	return i.DeleteRouteContext(context.Background(), id)
+}
+
+// DeleteRouteContext is like DeleteRoute but carries ctx through the request
+func (i *Inbound) DeleteRouteContext(ctx context.Context, id string) (InboundRouteResponse, error) {
+	var ret InboundRouteResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     string `json:"id"`
 	}{i.m.APIKey, id}
-	body, err := i.m.execute("/inbound/delete-route.json", data)
+	body, err := i.m.execute(ctx, "/inbound/delete-route.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/delete-route.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -175,8 +215,13 @@ func (i *Inbound) DeleteRoute(id string) (inboundRouteResponse, error) {
 
 // Take a raw MIME document destined for a domain with inbound domains set up
 // and send it to the inbound hook exactly as if it had been sent over SMTP
-func (i *Inbound) SendRaw(rawMessage string, to []string, from string, helo string, clientAddr string) ([]inboundSendRawResponse, error) {
-	var ret []inboundSendRawResponse
+func (i *Inbound) SendRaw(rawMessage string, to []string, from string, helo string, clientAddr string) ([]InboundSendRawResponse, error) {
+	return i.SendRawContext(context.Background(), rawMessage, to, from, helo, clientAddr)
+}
+
+// SendRawContext is like SendRaw but carries ctx through the request
+func (i *Inbound) SendRawContext(ctx context.Context, rawMessage string, to []string, from string, helo string, clientAddr string) ([]InboundSendRawResponse, error) {
+	var ret []InboundSendRawResponse
 	data := struct {
 		APIKey        string   `json:"key"`
 		RawMessage    string   `json:"raw_message"`
@@ -185,12 +230,12 @@ func (i *Inbound) SendRaw(rawMessage string, to []string, from string, helo stri
 		Helo          string   `json:"helo,omitempty"`
 		ClientAddress string   `json:"client_address,omitempty"`
 	}{i.m.APIKey, rawMessage, to, from, helo, clientAddr}
-	body, err := i.m.execute("/inbound/send-raw.json", data)
+	body, err := i.m.execute(ctx, "/inbound/send-raw.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/inbound/send-raw.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -198,7 +243,7 @@ func (i *Inbound) SendRaw(rawMessage string, to []string, from string, helo stri
 	return ret, nil
 }
 
-type inboundSendRawResponse struct {
+type InboundSendRawResponse struct {
 	Email   string `json:"email"`
 	Pattern string `json:"pattern"`
 	URL     string `json:"url"`
@@ -0,0 +1,165 @@
+package mandrill
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// imgSrc matches the src attribute of img tags, capturing the value in one of
+// the double quoted, single quoted or unquoted groups
+var imgSrc = regexp.MustCompile(`(?i)(<img\b[^>]*?\ssrc\s*=\s*)(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// EmbedImages replaces local and data URI image sources in msg.HTML with cid: references,
+// adding the images to msg.Images. Local sources are paths resolved against fsys, which may
+// be nil if only data URIs are used. Remote and cid: sources are left as they are.
+// Each distinct source is embedded once, with a content id unique within the message.
+// If an image cannot be embedded msg is left unchanged. opts apply to each image
+func (msg *Message) EmbedImages(fsys fs.FS, opts ...AttachmentOption) error {
+	used := make(map[string]bool)
+	for _, img := range msg.Images {
+		used[img.Name] = true
+	}
+	cids := make(map[string]string)
+	var images []*Image
+	var embedErr error
+
+	html := imgSrc.ReplaceAllStringFunc(msg.HTML, func(tag string) string {
+		if embedErr != nil {
+			return tag
+		}
+		m := imgSrc.FindStringSubmatch(tag)
+		src, quote := m[2], `"`
+		switch {
+		case m[3] != "":
+			src, quote = m[3], `'`
+		case m[4] != "":
+			src, quote = m[4], ""
+		}
+		if !isEmbeddable(src) {
+			return tag
+		}
+
+		key := sourceKey(src)
+		cid, ok := cids[key]
+		if !ok {
+			img, err := embedImage(fsys, src, opts)
+			if err != nil {
+				embedErr = fmt.Errorf("mandrill: embedding image %q: %w", truncate(src, 64), err)
+				return tag
+			}
+			img.Name = uniqueName(img.Name, used)
+			images = append(images, img)
+			cid = img.Name
+			cids[key] = cid
+		}
+		return m[1] + quote + "cid:" + cid + quote
+	})
+	if embedErr != nil {
+		return embedErr
+	}
+
+	msg.HTML = html
+	msg.Images = append(msg.Images, images...)
+	return nil
+}
+
+// isEmbeddable reports whether src is a data URI or a local path
+func isEmbeddable(src string) bool {
+	src = strings.TrimSpace(src)
+	if src == "" || strings.HasPrefix(src, "//") {
+		return false
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || strings.EqualFold(u.Scheme, "data")
+}
+
+// embedImage returns the image for src, named after its file or media type
+func embedImage(fsys fs.FS, src string, opts []AttachmentOption) (*Image, error) {
+	src = strings.TrimSpace(src)
+	if strings.HasPrefix(strings.ToLower(src), "data:") {
+		return dataImage(src, newAttachmentConfig(opts).maxSize)
+	}
+	if fsys == nil {
+		return nil, fmt.Errorf("no file system to resolve local images")
+	}
+	return NewImageFromFS(fsys, sourceKey(src), opts...)
+}
+
+// sourceKey returns the path in the file system for a local src, or src itself for a data URI
+func sourceKey(src string) string {
+	src = strings.TrimSpace(src)
+	if strings.HasPrefix(strings.ToLower(src), "data:") {
+		return src
+	}
+	if u, err := url.Parse(src); err == nil {
+		src = u.Path
+	}
+	return strings.TrimPrefix(path.Clean("/"+src), "/")
+}
+
+// dataImage decodes a data URI of the form data:image/png;base64,... of at most maxSize bytes
+func dataImage(src string, maxSize int64) (*Image, error) {
+	i := strings.Index(src, ",")
+	if i < 0 {
+		return nil, fmt.Errorf("malformed data URI")
+	}
+	params := strings.Split(src[len("data:"):i], ";")
+	typ := params[0]
+	isBase64 := false
+	for _, p := range params[1:] {
+		if strings.EqualFold(p, "base64") {
+			isBase64 = true
+		}
+	}
+
+	var content []byte
+	if isBase64 {
+		var err error
+		if content, err = base64.StdEncoding.DecodeString(src[i+1:]); err != nil {
+			return nil, err
+		}
+	} else {
+		s, err := url.PathUnescape(src[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(s)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	name := "image"
+	if exts, _ := mime.ExtensionsByType(typ); len(exts) > 0 {
+		name += exts[0]
+	}
+	return newImage(name, typ, base64.StdEncoding.EncodeToString(content))
+}
+
+// uniqueName returns name, or name with a numeric suffix if it is already used, marking it used
+func uniqueName(name string, used map[string]bool) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; used[name]; i++ {
+		name = base + "-" + strconv.Itoa(i) + ext
+	}
+	used[name] = true
+	return name
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,53 @@
+package mandrill
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbedImages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"logo.png":       {Data: testPNG},
+		"icons/logo.png": {Data: testPNG},
+	}
+	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString(testPNG)
+	msg := &Message{
+		HTML: `<img src="logo.png"><img alt="again" src='./logo.png'>` +
+			`<img src=icons/logo.png><img src="` + dataURI + `">` +
+			`<img src="https://example.com/remote.png"><img src="cid:existing">`,
+		Images: []*Image{{Name: "existing", Type: "image/png"}},
+	}
+	if err := msg.EmbedImages(fsys); err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := `<img src="cid:logo.png"><img alt="again" src='cid:logo.png'>` +
+		`<img src=cid:logo-2.png><img src="cid:image.png">` +
+		`<img src="https://example.com/remote.png"><img src="cid:existing">`
+	if msg.HTML != expected {
+		t.Errorf("unexpected html. Received: %s", msg.HTML)
+	}
+	if len(msg.Images) != 4 {
+		t.Errorf("expected 4 images. Received: %+v", msg.Images)
+		return
+	}
+	for _, img := range msg.Images[1:] {
+		if img.Type != "image/png" || img.Content != base64.StdEncoding.EncodeToString(testPNG) {
+			t.Errorf("unexpected image. Received: %+v", img)
+		}
+	}
+}
+
+func TestEmbedImagesMissing(t *testing.T) {
+	msg := &Message{HTML: `<img src="logo.png"><img src="missing.png">`}
+	err := msg.EmbedImages(fstest.MapFS{"logo.png": {Data: testPNG}})
+	if err == nil || !strings.Contains(err.Error(), "missing.png") {
+		t.Errorf("expected error for missing image. Received: %v", err)
+	}
+	if msg.HTML != `<img src="logo.png"><img src="missing.png">` || len(msg.Images) != 0 {
+		t.Errorf("expected message to be unchanged. Received: %+v", msg)
+	}
+}
@@ -1,14 +1,14 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type IPs struct {
 	m *Mandrill
 }
 
-type ipsResponse struct {
+type IPsResponse struct {
 	IP        string `json:"ip"`
 	CreatedAt string `json:"created_at"`
 	Pool      string `json:"pool"`
@@ -26,15 +26,20 @@ type ipsResponse struct {
 }
 
 // List dedicated IPs
-func (i *IPs) List() ([]ipsResponse, error) {
-	var ret []ipsResponse
+func (i *IPs) List() ([]IPsResponse, error) {
+	return i.ListContext(context.Background())
+}
+
+// ListContext is like List but carries ctx through the request
+func (i *IPs) ListContext(ctx context.Context) ([]IPsResponse, error) {
+	var ret []IPsResponse
 	data := simpleRequest{i.m.APIKey}
-	body, err := i.m.execute("/ips/list.json", data)
+	body, err := i.m.execute(ctx, "/ips/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -42,18 +47,23 @@ func (i *IPs) List() ([]ipsResponse, error) {
 	return ret, nil
 }
 
-func (i *IPs) Info(ip string) (ipsResponse, error) {
-	var ret ipsResponse
+func (i *IPs) Info(ip string) (IPsResponse, error) {
+	return i.InfoContext(context.Background(), ip)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (i *IPs) InfoContext(ctx context.Context, ip string) (IPsResponse, error) {
+	var ret IPsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		IP     string `json:"ip"`
 	}{i.m.APIKey, ip}
-	body, err := i.m.execute("/ips/info.json", data)
+	body, err := i.m.execute(ctx, "/ips/info.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/info.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -64,19 +74,24 @@ func (i *IPs) Info(ip string) (ipsResponse, error) {
 // Provision requests an additional dedicated IP for your account. Accounts may
 // have one outstanding request at any time, and provisioning requests are
 // processed within 24 hours.
-func (i *IPs) Provision(warmup bool, pool string) (ipsProvisionResponse, error) {
-	var ret ipsProvisionResponse
+func (i *IPs) Provision(warmup bool, pool string) (IPsProvisionResponse, error) {
+	return i.ProvisionContext(context.Background(), warmup, pool)
+}
+
+// ProvisionContext is like Provision but carries ctx through the request
+func (i *IPs) ProvisionContext(ctx context.Context, warmup bool, pool string) (IPsProvisionResponse, error) {
+	var ret IPsProvisionResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Warmup bool   `json:"warmup,omitempty"`
 		Pool   string `json:"pool,omitempty"`
 	}{i.m.APIKey, warmup, pool}
-	body, err := i.m.execute("/ips/provision.json", data)
+	body, err := i.m.execute(ctx, "/ips/provision.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/provision.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -84,7 +99,7 @@ func (i *IPs) Provision(warmup bool, pool string) (ipsProvisionResponse, error)
 	return ret, nil
 }
 
-type ipsProvisionResponse struct {
+type IPsProvisionResponse struct {
 	RequestedAt string `json:"requested_at"`
 }
 
@@ -92,18 +107,23 @@ type ipsProvisionResponse struct {
 // will gradually increase the percentage of your mail that is sent over the warming-up IP,
 // over a period of roughly 30 days. The rest of your mail will be sent over shared IPs or other
 // dedicated IPs in the same pool.
-func (i *IPs) StartWarmup(ip string) (ipsResponse, error) {
-	var ret ipsResponse
+func (i *IPs) StartWarmup(ip string) (IPsResponse, error) {
+	return i.StartWarmupContext(context.Background(), ip)
+}
+
+// StartWarmupContext is like StartWarmup but carries ctx through the request
+func (i *IPs) StartWarmupContext(ctx context.Context, ip string) (IPsResponse, error) {
+	var ret IPsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		IP     string `json:"ip"`
 	}{i.m.APIKey, ip}
-	body, err := i.m.execute("/ips/start-warmup.json", data)
+	body, err := i.m.execute(ctx, "/ips/start-warmup.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/start-warmup.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -111,18 +131,23 @@ func (i *IPs) StartWarmup(ip string) (ipsResponse, error) {
 	return ret, nil
 }
 
-func (i *IPs) CancelWarmup(ip string) (ipsResponse, error) {
-	var ret ipsResponse
+func (i *IPs) CancelWarmup(ip string) (IPsResponse, error) {
+	return i.CancelWarmupContext(context.Background(), ip)
+}
+
+// CancelWarmupContext is like CancelWarmup but carries ctx through the request
+func (i *IPs) CancelWarmupContext(ctx context.Context, ip string) (IPsResponse, error) {
+	var ret IPsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		IP     string `json:"ip"`
 	}{i.m.APIKey, ip}
-	body, err := i.m.execute("/ips/cancel-warmup.json", data)
+	body, err := i.m.execute(ctx, "/ips/cancel-warmup.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/cancel-warmup.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -131,20 +156,25 @@ func (i *IPs) CancelWarmup(ip string) (ipsResponse, error) {
 }
 
 // SetPool moves a dedicated IP to a different pool
-func (i *IPs) SetPool(ip string, pool string, createPool bool) (ipsResponse, error) {
-	var ret ipsResponse
+func (i *IPs) SetPool(ip string, pool string, createPool bool) (IPsResponse, error) {
+	return i.SetPoolContext(context.Background(), ip, pool, createPool)
+}
+
+// SetPoolContext is like SetPool but carries ctx through the request
+func (i *IPs) SetPoolContext(ctx context.Context, ip string, pool string, createPool bool) (IPsResponse, error) {
+	var ret IPsResponse
 	data := struct {
 		APIKey     string `json:"key"`
 		IP         string `json:"ip"`
 		Pool       string `json:"pool"`
 		CreatePool bool   `json:"create_pool,omitempty"`
 	}{i.m.APIKey, ip, pool, createPool}
-	body, err := i.m.execute("/ips/set-pool.json", data)
+	body, err := i.m.execute(ctx, "/ips/set-pool.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/set-pool.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -153,18 +183,23 @@ func (i *IPs) SetPool(ip string, pool string, createPool bool) (ipsResponse, err
 }
 
 // Delete a dedicated IP. This is permanent and cannot be undone.
-func (i *IPs) Delete(ip string) (ipsDeleteResponse, error) {
-	var ret ipsDeleteResponse
+func (i *IPs) Delete(ip string) (IPsDeleteResponse, error) {
+	return i.DeleteContext(context.Background(), ip)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (i *IPs) DeleteContext(ctx context.Context, ip string) (IPsDeleteResponse, error) {
+	var ret IPsDeleteResponse
 	data := struct {
 		APIKey string `json:"key"`
 		IP     string `json:"ip"`
 	}{i.m.APIKey, ip}
-	body, err := i.m.execute("/ips/delete.json", data)
+	body, err := i.m.execute(ctx, "/ips/delete.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/delete.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -172,21 +207,26 @@ func (i *IPs) Delete(ip string) (ipsDeleteResponse, error) {
 	return ret, nil
 }
 
-type ipsDeleteResponse struct {
+type IPsDeleteResponse struct {
 	IP      string `json:"ip"`
 	Deleted bool   `json:"deleted"`
 }
 
 // ListPools returns list of dedicated ip pools
-func (i *IPs) ListPools() ([]ipsPoolsResponse, error) {
-	var ret []ipsPoolsResponse
+func (i *IPs) ListPools() ([]IPsPoolsResponse, error) {
+	return i.ListPoolsContext(context.Background())
+}
+
+// ListPoolsContext is like ListPools but carries ctx through the request
+func (i *IPs) ListPoolsContext(ctx context.Context) ([]IPsPoolsResponse, error) {
+	var ret []IPsPoolsResponse
 	data := simpleRequest{i.m.APIKey}
-	body, err := i.m.execute("/ips/list-pools.json", data)
+	body, err := i.m.execute(ctx, "/ips/list-pools.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/list-pools.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -194,7 +234,7 @@ func (i *IPs) ListPools() ([]ipsPoolsResponse, error) {
 	return ret, nil
 }
 
-type ipsPoolsResponse struct {
+type IPsPoolsResponse struct {
 	Name      string `json:"name"`
 	CreatedAt string `json:"created_at"`
 	Ips       []struct {
@@ -215,18 +255,23 @@ type ipsPoolsResponse struct {
 	} `json:"ips"`
 }
 
-func (i *IPs) PoolInfo(pool string) (ipsPoolsResponse, error) {
-	var ret ipsPoolsResponse
+func (i *IPs) PoolInfo(pool string) (IPsPoolsResponse, error) {
+	return i.PoolInfoContext(context.Background(), pool)
+}
+
+// PoolInfoContext is like PoolInfo but carries ctx through the request
+func (i *IPs) PoolInfoContext(ctx context.Context, pool string) (IPsPoolsResponse, error) {
+	var ret IPsPoolsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Pool   string `json:"pool"`
 	}{i.m.APIKey, pool}
-	body, err := i.m.execute("/ips/pool-info.json", data)
+	body, err := i.m.execute(ctx, "/ips/pool-info.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/pool-info.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -234,18 +279,23 @@ func (i *IPs) PoolInfo(pool string) (ipsPoolsResponse, error) {
 	return ret, nil
 }
 
-func (i *IPs) CreatePool(pool string) (ipsPoolsResponse, error) {
-	var ret ipsPoolsResponse
+func (i *IPs) CreatePool(pool string) (IPsPoolsResponse, error) {
+	return i.CreatePoolContext(context.Background(), pool)
+}
+
+// CreatePoolContext is like CreatePool but carries ctx through the request
+func (i *IPs) CreatePoolContext(ctx context.Context, pool string) (IPsPoolsResponse, error) {
+	var ret IPsPoolsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Pool   string `json:"pool"`
 	}{i.m.APIKey, pool}
-	body, err := i.m.execute("/ips/create-pool.json", data)
+	body, err := i.m.execute(ctx, "/ips/create-pool.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/create-pool.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -253,18 +303,23 @@ func (i *IPs) CreatePool(pool string) (ipsPoolsResponse, error) {
 	return ret, nil
 }
 
-func (i *IPs) DeletePool(pool string) (ipsDeletePoolResponse, error) {
-	var ret ipsDeletePoolResponse
+func (i *IPs) DeletePool(pool string) (IPsDeletePoolResponse, error) {
+	return i.DeletePoolContext(context.Background(), pool)
+}
+
+// DeletePoolContext is like DeletePool but carries ctx through the request
+func (i *IPs) DeletePoolContext(ctx context.Context, pool string) (IPsDeletePoolResponse, error) {
+	var ret IPsDeletePoolResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Pool   string `json:"pool"`
 	}{i.m.APIKey, pool}
-	body, err := i.m.execute("/ips/delete-pool.json", data)
+	body, err := i.m.execute(ctx, "/ips/delete-pool.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/delete-pool.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -272,24 +327,29 @@ func (i *IPs) DeletePool(pool string) (ipsDeletePoolResponse, error) {
 	return ret, nil
 }
 
-type ipsDeletePoolResponse struct {
+type IPsDeletePoolResponse struct {
 	Pool    string `json:"pool"`
 	Deleted bool   `json:"deleted"`
 }
 
-func (i *IPs) CheckCustomDNS(ip string, domain string) (ipsCheckDNSResponse, error) {
-	var ret ipsCheckDNSResponse
+func (i *IPs) CheckCustomDNS(ip string, domain string) (IPsCheckDNSResponse, error) {
+	return i.CheckCustomDNSContext(context.Background(), ip, domain)
+}
+
+// CheckCustomDNSContext is like CheckCustomDNS but carries ctx through the request
+func (i *IPs) CheckCustomDNSContext(ctx context.Context, ip string, domain string) (IPsCheckDNSResponse, error) {
+	var ret IPsCheckDNSResponse
 	data := struct {
 		APIKey string `json:"key"`
 		IP     string `json:"ip"`
 		Domain string `json:"domain"`
 	}{i.m.APIKey, ip, domain}
-	body, err := i.m.execute("/ips/check-custom-dns.json", data)
+	body, err := i.m.execute(ctx, "/ips/check-custom-dns.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/check-custom-dns.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -297,24 +357,29 @@ func (i *IPs) CheckCustomDNS(ip string, domain string) (ipsCheckDNSResponse, err
 	return ret, nil
 }
 
-type ipsCheckDNSResponse struct {
+type IPsCheckDNSResponse struct {
 	Valid bool   `json:"valid"`
 	Error string `json:"error"`
 }
 
-func (i *IPs) SetCustomDNS(ip string, domain string) (ipsResponse, error) {
-	var ret ipsResponse
+func (i *IPs) SetCustomDNS(ip string, domain string) (IPsResponse, error) {
+	return i.SetCustomDNSContext(context.Background(), ip, domain)
+}
+
+// SetCustomDNSContext is like SetCustomDNS but carries ctx through the request
+func (i *IPs) SetCustomDNSContext(ctx context.Context, ip string, domain string) (IPsResponse, error) {
+	var ret IPsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		IP     string `json:"ip"`
 		Domain string `json:"domain"`
 	}{i.m.APIKey, ip, domain}
-	body, err := i.m.execute("/ips/check-custom-dns.json", data)
+	body, err := i.m.execute(ctx, "/ips/check-custom-dns.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/ips/check-custom-dns.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
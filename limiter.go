@@ -0,0 +1,199 @@
+package mandrill
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultQuotaRefreshInterval is how often a QuotaLimiter refreshes quotas when none is given
+const DefaultQuotaRefreshInterval = 5 * time.Minute
+
+// QuotaLimiter throttles message sending to stay within the hourly quota reported by
+// Users.Info, and the per-subaccount quota reported by Subaccounts.Info.
+// Quotas are treated as token buckets that refill continuously over the hour.
+// A backlog of queued messages reported by Mandrill is paid off before sending resumes.
+// If quotas cannot be refreshed, the last known values are used, and sending is not
+// throttled until a quota is known
+type QuotaLimiter struct {
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*quotaBucket
+}
+
+// quotaBucket tracks the sending allowance of the account ("") or a subaccount
+type quotaBucket struct {
+	// hourly quota. Zero means unknown
+	quota int
+
+	// messages that may be sent now. Negative while a backlog is being paid off
+	tokens float64
+
+	// the time tokens was last brought up to date
+	updated time.Time
+
+	// the time a refresh was last started
+	refreshed time.Time
+}
+
+// NewQuotaLimiter returns a limiter that refreshes quotas from the api every refreshInterval.
+// A zero interval uses DefaultQuotaRefreshInterval
+func NewQuotaLimiter(refreshInterval time.Duration) *QuotaLimiter {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultQuotaRefreshInterval
+	}
+	return &QuotaLimiter{
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+		buckets:         make(map[string]*quotaBucket),
+	}
+}
+
+// WithQuotaLimiter throttles Messages.Send callers using l
+func WithQuotaLimiter(l *QuotaLimiter) Option {
+	return func(m *Mandrill) {
+		m.Limiter = l
+	}
+}
+
+// Headroom returns the number of messages that can be sent now by the account, or by the
+// subaccount if one is given, based on the last refresh. ok is false if no quota is known yet
+func (l *QuotaLimiter) Headroom(subaccount string) (n int, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[subaccount]
+	if b == nil || b.quota == 0 {
+		return 0, false
+	}
+	b.fill(l.now())
+	if b.tokens < 0 {
+		return 0, true
+	}
+	return int(b.tokens), true
+}
+
+// QuotaHeadroom refreshes quotas if they are stale and returns the number of messages that
+// can be sent now by the account, or by the subaccount if one is given.
+// It returns -1 if no limiter is configured
+func (m *Mandrill) QuotaHeadroom(ctx context.Context, subaccount string) (int, error) {
+	if m.Limiter == nil {
+		return -1, nil
+	}
+	if err := m.Limiter.refresh(ctx, m, ""); err != nil {
+		return 0, err
+	}
+	if subaccount != "" {
+		if err := m.Limiter.refresh(ctx, m, subaccount); err != nil {
+			return 0, err
+		}
+	}
+	n, _ := m.Limiter.Headroom(subaccount)
+	return n, nil
+}
+
+// wait blocks until n messages can be sent by the account and subaccount, or ctx is done
+// Refresh errors are ignored so that an unavailable info endpoint does not block sending
+func (l *QuotaLimiter) wait(ctx context.Context, m *Mandrill, subaccount string, n int) error {
+	l.refresh(ctx, m, "")
+	if err := l.take(ctx, "", n); err != nil {
+		return err
+	}
+	if subaccount == "" {
+		return nil
+	}
+	l.refresh(ctx, m, subaccount)
+	return l.take(ctx, subaccount, n)
+}
+
+// take removes n tokens from the bucket, sleeping until enough have accumulated.
+// Requests larger than the quota wait for a full bucket rather than forever
+func (l *QuotaLimiter) take(ctx context.Context, key string, n int) error {
+	for {
+		l.mu.Lock()
+		b := l.buckets[key]
+		if b == nil || b.quota == 0 {
+			l.mu.Unlock()
+			return nil
+		}
+		b.fill(l.now())
+		need := float64(n)
+		if need > float64(b.quota) {
+			need = float64(b.quota)
+		}
+		if b.tokens >= need {
+			b.tokens -= float64(n)
+			l.mu.Unlock()
+			return nil
+		}
+		d := time.Duration((need - b.tokens) / float64(b.quota) * float64(time.Hour))
+		l.mu.Unlock()
+
+		if err := sleepContext(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// refresh updates the bucket for key from the api if it is stale. Only one caller
+// refreshes at a time; others continue with the last known values
+func (l *QuotaLimiter) refresh(ctx context.Context, m *Mandrill, key string) error {
+	l.mu.Lock()
+	b := l.buckets[key]
+	if b == nil {
+		b = &quotaBucket{}
+		l.buckets[key] = b
+	}
+	now := l.now()
+	if !b.refreshed.IsZero() && now.Sub(b.refreshed) < l.refreshInterval {
+		l.mu.Unlock()
+		return nil
+	}
+	b.refreshed = now
+	l.mu.Unlock()
+
+	// limit is the most tokens the api figures allow: the quota less what was
+	// sent this hour, or less the backlog queued beyond it
+	var quota int
+	var limit float64
+	if key == "" {
+		info, err := m.Users().InfoContext(ctx)
+		if err != nil {
+			return err
+		}
+		quota, limit = info.HourlyQuota, float64(info.HourlyQuota)
+		if info.Backlog > 0 {
+			limit = -float64(info.Backlog)
+		}
+	} else {
+		info, err := m.Subaccounts().InfoContext(ctx, key)
+		if err != nil {
+			return err
+		}
+		quota, limit = info.HourlyQuota, float64(info.HourlyQuota-info.SentHourly)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b.fill(l.now())
+	if b.quota == 0 {
+		b.tokens = float64(quota)
+	}
+	b.quota = quota
+	if limit < b.tokens {
+		b.tokens = limit
+	}
+	return nil
+}
+
+// fill adds the tokens accumulated since the last update
+func (b *quotaBucket) fill(now time.Time) {
+	if b.quota > 0 && now.After(b.updated) && !b.updated.IsZero() {
+		b.tokens += now.Sub(b.updated).Hours() * float64(b.quota)
+		if b.tokens > float64(b.quota) {
+			b.tokens = float64(b.quota)
+		}
+	}
+	b.updated = now
+}
@@ -0,0 +1,100 @@
+package mandrill
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newQuotaTestServer(t *testing.T, userInfo string, subaccountInfo string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/info.json":
+			w.Write([]byte(userInfo))
+		case "/subaccounts/info.json":
+			w.Write([]byte(subaccountInfo))
+		case "/messages/send.json", "/messages/send-raw.json":
+			w.Write([]byte(`[{"email":"to@example.com","status":"sent","_id":"abc"}]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestQuotaLimiterHeadroom(t *testing.T) {
+	ts := newQuotaTestServer(t, `{"hourly_quota":3600,"backlog":0}`, `{"id":"sub","hourly_quota":100,"sent_hourly":40}`)
+	defer ts.Close()
+
+	now := time.Date(2015, time.December, 4, 12, 0, 0, 0, time.UTC)
+	l := NewQuotaLimiter(time.Hour)
+	l.now = func() time.Time { return now }
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL), WithQuotaLimiter(l))
+
+	if n, err := m.QuotaHeadroom(context.Background(), ""); err != nil || n != 3600 {
+		t.Errorf("expected headroom 3600. Received: %d, %v", n, err)
+	}
+	if n, err := m.QuotaHeadroom(context.Background(), "sub"); err != nil || n != 60 {
+		t.Errorf("expected subaccount headroom 60. Received: %d, %v", n, err)
+	}
+
+	msg := &Message{FromEmail: "from@example.com", To: []Recipient{{Email: "to@example.com"}}, SubAccount: "sub"}
+	if _, err := m.Messages().Send(msg, false, "", nil); err != nil {
+		t.Error(err)
+		return
+	}
+	if n, _ := l.Headroom(""); n != 3599 {
+		t.Errorf("expected headroom 3599 after send. Received: %d", n)
+	}
+	if n, _ := l.Headroom("sub"); n != 59 {
+		t.Errorf("expected subaccount headroom 59 after send. Received: %d", n)
+	}
+
+	// raw messages count their header recipients against the subaccount in X-MC-Subaccount
+	raw := "From: from@example.com\r\nTo: a@example.com, b@example.com\r\nX-MC-Subaccount: sub\r\n\r\nHi\r\n"
+	if _, err := m.Messages().SendRaw(raw, nil); err != nil {
+		t.Error(err)
+		return
+	}
+	if n, _ := l.Headroom(""); n != 3597 {
+		t.Errorf("expected headroom 3597 after raw send. Received: %d", n)
+	}
+	if n, _ := l.Headroom("sub"); n != 57 {
+		t.Errorf("expected subaccount headroom 57 after raw send. Received: %d", n)
+	}
+
+	// one token per second refills the account bucket
+	now = now.Add(3 * time.Second)
+	if n, _ := l.Headroom(""); n != 3600 {
+		t.Errorf("expected headroom 3600 after refill. Received: %d", n)
+	}
+}
+
+func TestQuotaLimiterBacklog(t *testing.T) {
+	ts := newQuotaTestServer(t, `{"hourly_quota":10,"backlog":5}`, `{}`)
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL), WithQuotaLimiter(NewQuotaLimiter(0)))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	msg := &Message{FromEmail: "from@example.com", To: []Recipient{{Email: "to@example.com"}}}
+	if _, err := m.Messages().SendContext(ctx, msg, false, "", nil); err != context.DeadlineExceeded {
+		t.Errorf("expected send to wait for backlog. Received: %v", err)
+	}
+
+	sendAt := time.Now().Add(time.Hour)
+	if _, err := m.Messages().Send(msg, false, "", &sendAt); err != nil {
+		t.Errorf("expected scheduled send to skip limiter. Received: %v", err)
+	}
+}
+
+func TestQuotaLimiterUnknown(t *testing.T) {
+	l := NewQuotaLimiter(0)
+	if _, ok := l.Headroom(""); ok {
+		t.Error("expected unknown headroom before refresh")
+	}
+	if err := l.take(context.Background(), "", 100); err != nil {
+		t.Errorf("expected no throttling without quota. Received: %v", err)
+	}
+}
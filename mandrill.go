@@ -3,23 +3,58 @@ package mandrill
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 )
 
 const APIBaseURL = "https://mandrillapp.com/api/1.0"
 
+// defaultBaseURL is used when Mandrill.BaseURL is empty. Tests point it at a mandrilltest server
+var defaultBaseURL = APIBaseURL
+
+// DefaultTimeout is the request timeout used when no HttpClient is given
+const DefaultTimeout = 30 * time.Second
+
+// defaultUserAgent is sent with every request, followed by Mandrill.UserAgent if set
+const defaultUserAgent = "Mandrill Go"
+
+var defaultHTTPClient = &http.Client{Timeout: DefaultTimeout}
+
 type Mandrill struct {
 	APIKey     string
 	HttpClient *http.Client
+
+	// BaseURL overrides APIBaseURL, e.g. to point at a proxy or local server
+	BaseURL string
+
+	// UserAgent is appended to the default User-Agent header
+	UserAgent string
+
+	// Retry controls retrying of transient failures. Nil means no retries
+	Retry *RetryPolicy
+
+	// Limiter throttles message sending to stay within hourly quotas. Nil means no throttling
+	Limiter *QuotaLimiter
+
+	// Middleware observes or modifies every api call. The first is outermost
+	Middleware []Middleware
+
+	// ValidateMessages runs Message.Validate before sending, returning its error without calling the api
+	ValidateMessages bool
 }
 
-func NewMandrill(apikey string) Mandrill {
-	return Mandrill{APIKey: apikey}
+// NewMandrill returns a Mandrill for the given API key, configured by opts
+func NewMandrill(apikey string, opts ...Option) Mandrill {
+	m := Mandrill{APIKey: apikey}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return m
 }
 
 // simpleRequest represents requests that only require an api key
@@ -27,8 +62,9 @@ type simpleRequest struct {
 	APIkey string `json:"key"`
 }
 
-// execute sends POST request to the api server
-func (m *Mandrill) execute(path string, obj interface{}) ([]byte, error) {
+// execute sends POST request to the api server through any middleware, retrying according
+// to m.Retry. If ctx is cancelled or its deadline passes before a response is read, ctx.Err() is returned
+func (m *Mandrill) execute(ctx context.Context, path string, obj interface{}) ([]byte, error) {
 	if obj == nil {
 		return nil, errors.New("empty request")
 	}
@@ -36,31 +72,69 @@ func (m *Mandrill) execute(path string, obj interface{}) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	var buf bytes.Buffer
-	_, err = buf.Write(jsonBytes)
-	if err != nil {
-		return nil, err
+
+	if len(m.Middleware) > 0 {
+		return m.executeMiddleware(ctx, path, jsonBytes)
 	}
+	return m.executeRetry(ctx, path, jsonBytes)
+}
 
-	url := APIBaseURL + path
-	req, err := http.NewRequest("POST", url, &buf)
+// executeRetry sends body to path, retrying according to m.Retry
+func (m *Mandrill) executeRetry(ctx context.Context, path string, body []byte) ([]byte, error) {
+	policy := m.Retry
+	if policy == nil {
+		policy = &RetryPolicy{MaxAttempts: 1}
+	}
+
+	for attempt := 1; ; attempt++ {
+		respB, status, err := m.do(ctx, path, body)
+		if err == nil {
+			return respB, nil
+		}
+		if attempt >= policy.MaxAttempts || !policy.retryable(path, status, err) {
+			if attempt > 1 && ctx.Err() == nil {
+				return nil, &RetryError{Attempts: attempt, Err: err}
+			}
+			return nil, err
+		}
+		if err := sleepContext(ctx, policy.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do makes a single attempt at sending body to path. status is zero if no response was received
+func (m *Mandrill) do(ctx context.Context, path string, body []byte) ([]byte, int, error) {
+	baseURL := defaultBaseURL
+	if m.BaseURL != "" {
+		baseURL = strings.TrimSuffix(m.BaseURL, "/")
+	}
+	url := baseURL + path
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
-	req.Header.Set("User-Agent", "Mandrill Go")
+	userAgent := defaultUserAgent
+	if m.UserAgent != "" {
+		userAgent += " " + m.UserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
 
 	httpClient := m.HttpClient
 	if httpClient == nil {
-		httpClient = http.DefaultClient
+		httpClient = defaultHTTPClient
 	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		return nil, 0, &TransportError{Path: path, Err: err}
 	}
 	defer resp.Body.Close()
 
@@ -69,16 +143,22 @@ func (m *Mandrill) execute(path string, obj interface{}) ([]byte, error) {
 	case "gzip":
 		g, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, err
+			return nil, resp.StatusCode, &TransportError{Path: path, Err: err}
 		}
 		respB, err = ioutil.ReadAll(g)
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil {
+				return nil, resp.StatusCode, ctx.Err()
+			}
+			return nil, resp.StatusCode, &TransportError{Path: path, Err: err}
 		}
 	default:
 		respB, err = ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil {
+				return nil, resp.StatusCode, ctx.Err()
+			}
+			return nil, resp.StatusCode, &TransportError{Path: path, Err: err}
 		}
 	}
 
@@ -86,13 +166,28 @@ func (m *Mandrill) execute(path string, obj interface{}) ([]byte, error) {
 	if resp.StatusCode != http.StatusOK {
 		var errResponse *APIError
 		if err = json.Unmarshal(respB, &errResponse); err != nil {
-			return nil, fmt.Errorf("failed to interpret api error. Error Response: %s", err)
-		} else {
-			return nil, errResponse
+			return nil, resp.StatusCode, &DecodeError{Path: path, HTTPStatus: resp.StatusCode, Body: respB, Err: err}
 		}
+		if errResponse == nil || errResponse.Name == "" {
+			err = errors.New("missing error name")
+			return nil, resp.StatusCode, &DecodeError{Path: path, HTTPStatus: resp.StatusCode, Body: respB, Err: err}
+		}
+		errResponse.HTTPStatus = resp.StatusCode
+		errResponse.Path = path
+		errResponse.Body = respB
+		return nil, resp.StatusCode, errResponse
 	}
 
-	return respB, nil
+	return respB, resp.StatusCode, nil
+}
+
+// decode unmarshals the successful response body from path into v, returning a *DecodeError
+// if it does not match
+func decode(path string, body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return &DecodeError{Path: path, HTTPStatus: http.StatusOK, Body: body, Err: err}
+	}
+	return nil
 }
 
 // FromMandrillTime returns a time struct in UTC
@@ -105,58 +200,58 @@ func ToMandrillTime(t time.Time) string {
 	return t.UTC().Format("2006-01-02 15:04:05")
 }
 
-func (m *Mandrill) Users() *Users {
+func (m *Mandrill) Users() UsersAPI {
 	return &Users{m}
 }
 
-func (m *Mandrill) Messages() *Messages {
+func (m *Mandrill) Messages() MessagesAPI {
 	return &Messages{m}
 }
 
-func (m *Mandrill) Tags() *Tags {
+func (m *Mandrill) Tags() TagsAPI {
 	return &Tags{m}
 }
 
-func (m *Mandrill) Rejects() *Rejects {
+func (m *Mandrill) Rejects() RejectsAPI {
 	return &Rejects{m}
 }
 
-func (m *Mandrill) Whitelists() *Whitelists {
+func (m *Mandrill) Whitelists() WhitelistsAPI {
 	return &Whitelists{m}
 }
 
-func (m *Mandrill) Senders() *Senders {
+func (m *Mandrill) Senders() SendersAPI {
 	return &Senders{m}
 }
 
-func (m *Mandrill) URLs() *URLs {
+func (m *Mandrill) URLs() URLsAPI {
 	return &URLs{m}
 }
 
-func (m *Mandrill) Templates() *Templates {
+func (m *Mandrill) Templates() TemplatesAPI {
 	return &Templates{m}
 }
 
-func (m *Mandrill) Webhooks() *Webhooks {
+func (m *Mandrill) Webhooks() WebhooksAPI {
 	return &Webhooks{m}
 }
 
-func (m *Mandrill) Subaccounts() *Subaccounts {
+func (m *Mandrill) Subaccounts() SubaccountsAPI {
 	return &Subaccounts{m}
 }
 
-func (m *Mandrill) Inbound() *Inbound {
+func (m *Mandrill) Inbound() InboundAPI {
 	return &Inbound{m}
 }
 
-func (m *Mandrill) Exports() *Exports {
+func (m *Mandrill) Exports() ExportsAPI {
 	return &Exports{m}
 }
 
-func (m *Mandrill) IPs() *IPs {
+func (m *Mandrill) IPs() IPsAPI {
 	return &IPs{m}
 }
 
-func (m *Mandrill) Metadata() *Metadata {
+func (m *Mandrill) Metadata() MetadataAPI {
 	return &Metadata{m}
 }
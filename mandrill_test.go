@@ -1,10 +1,13 @@
 package mandrill
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
 	"time"
+
+	"github.com/jimtsao/mandrill/mandrilltest"
 )
 
 var TestAPIKey string
@@ -13,6 +16,16 @@ var TestFromEmail string
 func TestMain(m *testing.M) {
 	TestAPIKey = os.Getenv("MANDRILL_TEST_API_KEY")
 	TestFromEmail = os.Getenv("MANDRILL_TEST_FROM_EMAIL")
+	if TestAPIKey == "" && TestFromEmail == "" {
+		fmt.Println("MANDRILL_TEST_API_KEY and MANDRILL_TEST_FROM_EMAIL not set, testing against mandrilltest server")
+		s := mandrilltest.NewServer()
+		defaultBaseURL = s.URL
+		TestAPIKey = mandrilltest.APIKey
+		TestFromEmail = "sender@example.com"
+		code := m.Run()
+		s.Close()
+		os.Exit(code)
+	}
 	if TestAPIKey == "" || TestFromEmail == "" {
 		fmt.Println("Please set all ENV variables MANDRILL_TEST_API_KEY, MANDRILL_TEST_FROM_EMAIL")
 		os.Exit(1)
@@ -69,3 +82,13 @@ func TestReadmeExamples(t *testing.T) {
 		}
 	}
 }
+
+func TestExecuteCancelledContext(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := m.Users().PingContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("expected context canceled error. Received: %v", err)
+	}
+}
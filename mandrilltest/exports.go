@@ -0,0 +1,80 @@
+package mandrilltest
+
+import (
+	"sort"
+	"time"
+)
+
+func init() {
+	route("/exports/info.json", exportsInfo)
+	route("/exports/list.json", exportsList)
+	route("/exports/rejects.json", exportsRejects)
+	route("/exports/whitelist.json", exportsWhitelist)
+	route("/exports/activity.json", exportsActivity)
+}
+
+// export is an export job. Jobs complete immediately but produce no downloadable result
+type export struct {
+	id        string
+	kind      string
+	createdAt time.Time
+}
+
+func (e *export) response() map[string]interface{} {
+	return map[string]interface{}{
+		"id":          e.id,
+		"created_at":  formatTime(e.createdAt),
+		"type":        e.kind,
+		"finished_at": formatTime(e.createdAt),
+		"state":       "complete",
+		"result_url":  "",
+	}
+}
+
+// addExport starts an export job of the given kind
+func (s *Server) addExport(kind string, body []byte) (interface{}, error) {
+	var req struct {
+		NotifyEmail string `json:"notify_email"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	e := &export{s.newId(), kind, time.Now().UTC()}
+	s.exports[e.id] = e
+	return e.response(), nil
+}
+
+func exportsInfo(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Id string `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	e := s.exports[req.Id]
+	if e == nil {
+		return nil, &apiError{"error", 10, "Unknown_Export", "No export exists with the id '" + req.Id + "'"}
+	}
+	return e.response(), nil
+}
+
+func exportsList(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, e := range s.exports {
+		ret = append(ret, e.response())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["id"].(string) < ret[j]["id"].(string) })
+	return ret, nil
+}
+
+func exportsRejects(s *Server, body []byte) (interface{}, error) {
+	return s.addExport("reject", body)
+}
+
+func exportsWhitelist(s *Server, body []byte) (interface{}, error) {
+	return s.addExport("whitelist", body)
+}
+
+func exportsActivity(s *Server, body []byte) (interface{}, error) {
+	return s.addExport("activity", body)
+}
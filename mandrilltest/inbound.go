@@ -0,0 +1,246 @@
+package mandrilltest
+
+import (
+	"net/mail"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/inbound/domains.json", inboundDomains)
+	route("/inbound/add-domain.json", inboundAddDomain)
+	route("/inbound/check-domain.json", inboundCheckDomain)
+	route("/inbound/delete-domain.json", inboundDeleteDomain)
+	route("/inbound/routes.json", inboundRoutes)
+	route("/inbound/add-route.json", inboundAddRoute)
+	route("/inbound/update-route.json", inboundUpdateRoute)
+	route("/inbound/delete-route.json", inboundDeleteRoute)
+	route("/inbound/send-raw.json", inboundSendRaw)
+}
+
+// inboundDomain is a domain receiving inbound mail. Domains are reported as having valid MX records
+type inboundDomain struct {
+	domain    string
+	createdAt time.Time
+}
+
+func (d *inboundDomain) response() map[string]interface{} {
+	return map[string]interface{}{
+		"domain":     d.domain,
+		"created_at": formatTime(d.createdAt),
+		"valid_mx":   true,
+	}
+}
+
+// inboundRoute sends mail matching a mailbox pattern on a domain to a webhook url
+type inboundRoute struct {
+	id      string
+	domain  string
+	pattern string
+	url     string
+}
+
+func (r *inboundRoute) response() map[string]interface{} {
+	return map[string]interface{}{"id": r.id, "pattern": r.pattern, "url": r.url}
+}
+
+// inboundDomainArg decodes the domain parameter and returns the domain
+func (s *Server) inboundDomainArg(body []byte) (*inboundDomain, error) {
+	var req struct {
+		Domain string `json:"domain"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	d := s.inboundDomains[strings.ToLower(req.Domain)]
+	if d == nil {
+		return nil, &apiError{"error", 8, "Unknown_InboundDomain", "No inbound domain exists with the name '" + req.Domain + "'"}
+	}
+	return d, nil
+}
+
+// inboundRouteArg decodes the id parameter and returns the route
+func (s *Server) inboundRouteArg(body []byte) (*inboundRoute, error) {
+	var req struct {
+		Id string `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	r := s.inboundRoutes[req.Id]
+	if r == nil {
+		return nil, &apiError{"error", 9, "Unknown_InboundRoute", "No route exists with the id '" + req.Id + "'"}
+	}
+	return r, nil
+}
+
+func inboundDomains(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, d := range s.inboundDomains {
+		ret = append(ret, d.response())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["domain"].(string) < ret[j]["domain"].(string) })
+	return ret, nil
+}
+
+func inboundAddDomain(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Domain string `json:"domain"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Domain == "" {
+		return nil, errValidation("Validation error: {\"domain\":\"Please enter a value\"}")
+	}
+	key := strings.ToLower(req.Domain)
+	d := s.inboundDomains[key]
+	if d == nil {
+		d = &inboundDomain{req.Domain, time.Now().UTC()}
+		s.inboundDomains[key] = d
+	}
+	return d.response(), nil
+}
+
+func inboundCheckDomain(s *Server, body []byte) (interface{}, error) {
+	d, err := s.inboundDomainArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return d.response(), nil
+}
+
+func inboundDeleteDomain(s *Server, body []byte) (interface{}, error) {
+	d, err := s.inboundDomainArg(body)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.inboundDomains, strings.ToLower(d.domain))
+	for id, r := range s.inboundRoutes {
+		if r.domain == d.domain {
+			delete(s.inboundRoutes, id)
+		}
+	}
+	return d.response(), nil
+}
+
+// routes returns the routes of domain ordered by id
+func (s *Server) routes(domain string) []*inboundRoute {
+	var ret []*inboundRoute
+	for _, r := range s.inboundRoutes {
+		if strings.EqualFold(r.domain, domain) {
+			ret = append(ret, r)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].id < ret[j].id })
+	return ret
+}
+
+func inboundRoutes(s *Server, body []byte) (interface{}, error) {
+	d, err := s.inboundDomainArg(body)
+	if err != nil {
+		return nil, err
+	}
+	ret := []map[string]interface{}{}
+	for _, r := range s.routes(d.domain) {
+		ret = append(ret, r.response())
+	}
+	return ret, nil
+}
+
+func inboundAddRoute(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Pattern string `json:"pattern"`
+		URL     string `json:"url"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	d, err := s.inboundDomainArg(body)
+	if err != nil {
+		return nil, err
+	}
+	if req.Pattern == "" || req.URL == "" {
+		return nil, errValidation("Validation error: {\"pattern\":\"Please enter a value\",\"url\":\"Please enter a value\"}")
+	}
+	r := &inboundRoute{s.newId(), d.domain, req.Pattern, req.URL}
+	s.inboundRoutes[r.id] = r
+	return r.response(), nil
+}
+
+func inboundUpdateRoute(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Pattern string `json:"pattern"`
+		URL     string `json:"url"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	r, err := s.inboundRouteArg(body)
+	if err != nil {
+		return nil, err
+	}
+	if req.Pattern != "" {
+		r.pattern = req.Pattern
+	}
+	if req.URL != "" {
+		r.url = req.URL
+	}
+	return r.response(), nil
+}
+
+func inboundDeleteRoute(s *Server, body []byte) (interface{}, error) {
+	r, err := s.inboundRouteArg(body)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.inboundRoutes, r.id)
+	return r.response(), nil
+}
+
+// inboundSendRaw matches each recipient against the routes of its domain. Recipients default
+// to the To and Cc headers of the raw message. Webhooks are not called
+func inboundSendRaw(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		RawMessage string   `json:"raw_message"`
+		To         []string `json:"to"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.RawMessage == "" {
+		return nil, errValidation("Validation error: {\"raw_message\":\"Please enter a value\"}")
+	}
+
+	to := req.To
+	if len(to) == 0 {
+		msg, err := mail.ReadMessage(strings.NewReader(req.RawMessage))
+		if err != nil {
+			return nil, errValidation("Could not parse raw message: %s", err)
+		}
+		for _, h := range []string{"To", "Cc"} {
+			addrs, _ := msg.Header.AddressList(h)
+			for _, a := range addrs {
+				to = append(to, a.Address)
+			}
+		}
+	}
+
+	ret := []map[string]interface{}{}
+	for _, addr := range to {
+		at := strings.LastIndex(addr, "@")
+		if at < 0 {
+			continue
+		}
+		mailbox, domain := strings.ToLower(addr[:at]), addr[at+1:]
+		for _, r := range s.routes(domain) {
+			if ok, _ := path.Match(strings.ToLower(r.pattern), mailbox); ok {
+				ret = append(ret, map[string]interface{}{"email": addr, "pattern": r.pattern, "url": r.url})
+				break
+			}
+		}
+	}
+	return ret, nil
+}
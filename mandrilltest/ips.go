@@ -0,0 +1,269 @@
+package mandrilltest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+func init() {
+	route("/ips/list.json", ipsList)
+	route("/ips/info.json", ipsInfo)
+	route("/ips/provision.json", ipsProvision)
+	route("/ips/start-warmup.json", ipsStartWarmup)
+	route("/ips/cancel-warmup.json", ipsCancelWarmup)
+	route("/ips/set-pool.json", ipsSetPool)
+	route("/ips/delete.json", ipsDelete)
+	route("/ips/list-pools.json", ipsListPools)
+	route("/ips/pool-info.json", ipsPoolInfo)
+	route("/ips/create-pool.json", ipsCreatePool)
+	route("/ips/delete-pool.json", ipsDeletePool)
+	route("/ips/check-custom-dns.json", ipsCheckCustomDNS)
+	route("/ips/set-custom-dns.json", ipsSetCustomDNS)
+}
+
+// defaultPool is the pool that always exists and receives newly provisioned ips
+const defaultPool = "Main Pool"
+
+// ip is a dedicated ip. Ips are provisioned immediately
+type ip struct {
+	ip        string
+	pool      string
+	domain    string
+	createdAt time.Time
+	warmupAt  time.Time
+}
+
+func (i *ip) response() map[string]interface{} {
+	warmup := map[string]interface{}{"warming_up": false, "start_at": "", "end_at": ""}
+	if !i.warmupAt.IsZero() {
+		warmup = map[string]interface{}{
+			"warming_up": true,
+			"start_at":   formatTime(i.warmupAt),
+			"end_at":     formatTime(i.warmupAt.AddDate(0, 0, 30)),
+		}
+	}
+	return map[string]interface{}{
+		"ip":         i.ip,
+		"created_at": formatTime(i.createdAt),
+		"pool":       i.pool,
+		"domain":     i.domain,
+		"custom_dns": map[string]interface{}{"enabled": i.domain != "", "valid": i.domain != "", "error": ""},
+		"warmup":     warmup,
+	}
+}
+
+type pool struct {
+	name      string
+	createdAt time.Time
+}
+
+func (s *Server) poolResponse(p *pool) map[string]interface{} {
+	ips := []map[string]interface{}{}
+	for _, i := range s.sortedIPs() {
+		if i.pool == p.name {
+			ips = append(ips, i.response())
+		}
+	}
+	return map[string]interface{}{"name": p.name, "created_at": formatTime(p.createdAt), "ips": ips}
+}
+
+func (s *Server) sortedIPs() []*ip {
+	var ret []*ip
+	for _, i := range s.ips {
+		ret = append(ret, i)
+	}
+	sort.Slice(ret, func(a, b int) bool { return ret[a].ip < ret[b].ip })
+	return ret
+}
+
+// ipRequest holds the parameters of the ip endpoints
+type ipRequest struct {
+	IP         string `json:"ip"`
+	Pool       string `json:"pool"`
+	Domain     string `json:"domain"`
+	Warmup     bool   `json:"warmup"`
+	CreatePool bool   `json:"create_pool"`
+}
+
+// ipArg decodes the ip parameters and returns the ip
+func (s *Server) ipArg(body []byte) (*ip, *ipRequest, error) {
+	var req ipRequest
+	if err := decode(body, &req); err != nil {
+		return nil, nil, err
+	}
+	i := s.ips[req.IP]
+	if i == nil {
+		return nil, nil, &apiError{"error", 13, "Unknown_IP", "No dedicated IP exists with the address '" + req.IP + "'"}
+	}
+	return i, &req, nil
+}
+
+func errUnknownPool(name string) *apiError {
+	return &apiError{"error", 14, "Unknown_Pool", "No pool exists with the name '" + name + "'"}
+}
+
+func ipsList(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, i := range s.sortedIPs() {
+		ret = append(ret, i.response())
+	}
+	return ret, nil
+}
+
+func ipsInfo(s *Server, body []byte) (interface{}, error) {
+	i, _, err := s.ipArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return i.response(), nil
+}
+
+func ipsProvision(s *Server, body []byte) (interface{}, error) {
+	var req ipRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	name := req.Pool
+	if name == "" {
+		name = defaultPool
+	}
+	if s.pools[name] == nil {
+		return nil, errUnknownPool(name)
+	}
+	now := time.Now().UTC()
+	i := &ip{ip: fmt.Sprintf("192.0.2.%d", len(s.ips)+1), pool: name, createdAt: now}
+	if req.Warmup {
+		i.warmupAt = now
+	}
+	s.ips[i.ip] = i
+	return map[string]interface{}{"requested_at": formatTime(now)}, nil
+}
+
+func ipsStartWarmup(s *Server, body []byte) (interface{}, error) {
+	i, _, err := s.ipArg(body)
+	if err != nil {
+		return nil, err
+	}
+	i.warmupAt = time.Now().UTC()
+	return i.response(), nil
+}
+
+func ipsCancelWarmup(s *Server, body []byte) (interface{}, error) {
+	i, _, err := s.ipArg(body)
+	if err != nil {
+		return nil, err
+	}
+	i.warmupAt = time.Time{}
+	return i.response(), nil
+}
+
+func ipsSetPool(s *Server, body []byte) (interface{}, error) {
+	i, req, err := s.ipArg(body)
+	if err != nil {
+		return nil, err
+	}
+	if s.pools[req.Pool] == nil {
+		if !req.CreatePool {
+			return nil, errUnknownPool(req.Pool)
+		}
+		s.pools[req.Pool] = &pool{req.Pool, time.Now().UTC()}
+	}
+	i.pool = req.Pool
+	return i.response(), nil
+}
+
+func ipsDelete(s *Server, body []byte) (interface{}, error) {
+	i, _, err := s.ipArg(body)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.ips, i.ip)
+	return map[string]interface{}{"ip": i.ip, "deleted": true}, nil
+}
+
+func ipsListPools(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, p := range s.pools {
+		ret = append(ret, s.poolResponse(p))
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["name"].(string) < ret[j]["name"].(string) })
+	return ret, nil
+}
+
+// poolArg decodes the pool parameter and returns the pool
+func (s *Server) poolArg(body []byte) (*pool, error) {
+	var req ipRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	p := s.pools[req.Pool]
+	if p == nil {
+		return nil, errUnknownPool(req.Pool)
+	}
+	return p, nil
+}
+
+func ipsPoolInfo(s *Server, body []byte) (interface{}, error) {
+	p, err := s.poolArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return s.poolResponse(p), nil
+}
+
+func ipsCreatePool(s *Server, body []byte) (interface{}, error) {
+	var req ipRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Pool == "" {
+		return nil, errValidation("Validation error: {\"pool\":\"Please enter a value\"}")
+	}
+	p := s.pools[req.Pool]
+	if p == nil {
+		p = &pool{req.Pool, time.Now().UTC()}
+		s.pools[p.name] = p
+	}
+	return s.poolResponse(p), nil
+}
+
+func ipsDeletePool(s *Server, body []byte) (interface{}, error) {
+	p, err := s.poolArg(body)
+	if err != nil {
+		return nil, err
+	}
+	if p.name == defaultPool {
+		return nil, &apiError{"error", 15, "Invalid_DeleteDefaultPool", "The default pool cannot be deleted"}
+	}
+	for _, i := range s.ips {
+		if i.pool == p.name {
+			return nil, &apiError{"error", 16, "Invalid_DeleteNonEmptyPool", "Pools containing dedicated IPs cannot be deleted"}
+		}
+	}
+	delete(s.pools, p.name)
+	return map[string]interface{}{"pool": p.name, "deleted": true}, nil
+}
+
+func ipsCheckCustomDNS(s *Server, body []byte) (interface{}, error) {
+	_, req, err := s.ipArg(body)
+	if err != nil {
+		return nil, err
+	}
+	if req.Domain == "" {
+		return map[string]interface{}{"valid": false, "error": "No domain given"}, nil
+	}
+	return map[string]interface{}{"valid": true, "error": ""}, nil
+}
+
+func ipsSetCustomDNS(s *Server, body []byte) (interface{}, error) {
+	i, req, err := s.ipArg(body)
+	if err != nil {
+		return nil, err
+	}
+	if req.Domain == "" {
+		return nil, &apiError{"error", 17, "Invalid_CustomDNS", "No domain given"}
+	}
+	i.domain = req.Domain
+	return i.response(), nil
+}
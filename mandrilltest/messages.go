@@ -0,0 +1,840 @@
+package mandrilltest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/messages/send.json", messagesSend)
+	route("/messages/send-template.json", messagesSendTemplate)
+	route("/messages/search.json", messagesSearch)
+	route("/messages/search-time-series.json", messagesSearchTimeSeries)
+	route("/messages/info.json", messagesInfo)
+	route("/messages/content.json", messagesContent)
+	route("/messages/list-scheduled.json", messagesListScheduled)
+	route("/messages/cancel-scheduled.json", messagesCancelScheduled)
+	route("/messages/reschedule.json", messagesReschedule)
+	route("/messages/send-raw.json", messagesSendRaw)
+	route("/messages/parse.json", messagesParse)
+}
+
+// SentMessage is the record of a message sent to one recipient
+type SentMessage struct {
+	// the message's unique id
+	Id string
+
+	// the time the message was accepted
+	TS time.Time
+
+	// the recipient's email address
+	Email string
+
+	// the sender's email address
+	Sender string
+
+	Subject string
+
+	// the html content after templates and mailchimp merge tags are rendered
+	HTML string
+
+	// one of "sent", "queued", "scheduled", "rejected" or "invalid"
+	State string
+
+	// the reason for the rejection if State is "rejected"
+	RejectReason string
+
+	Tags       []string
+	Subaccount string
+
+	// the slug of the template used, if any
+	Template string
+
+	// the message metadata merged with the recipient's metadata
+	Metadata map[string]string
+
+	// the time the message is scheduled for, if State is "scheduled"
+	SendAt time.Time
+
+	// the message as sent in the request
+	Message json.RawMessage
+}
+
+// Messages returns the messages sent so far, one per recipient
+func (s *Server) Messages() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make([]SentMessage, len(s.messages))
+	for i, m := range s.messages {
+		ret[i] = *m
+	}
+	return ret
+}
+
+// message holds the fields of a message that affect the server's behaviour
+type message struct {
+	HTML      string `json:"html"`
+	Text      string `json:"text"`
+	Subject   string `json:"subject"`
+	FromEmail string `json:"from_email"`
+	FromName  string `json:"from_name"`
+	To        []struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+		Type  string `json:"type"`
+	} `json:"to"`
+	Tags              []string          `json:"tags"`
+	SubAccount        string            `json:"subaccount"`
+	Metadata          map[string]string `json:"metadata"`
+	RecipientMetadata []struct {
+		Rcpt   string            `json:"rcpt"`
+		Values map[string]string `json:"values"`
+	} `json:"recipient_metadata"`
+	Headers     map[string]string `json:"headers"`
+	Attachments []struct {
+		Name    string `json:"name"`
+		Type    string `json:"type"`
+		Content string `json:"content"`
+	} `json:"attachments"`
+	MergeLanguage   string        `json:"merge_language"`
+	GlobalMergeVars []templateVar `json:"global_merge_vars"`
+	MergeVars       []struct {
+		Rcpt string        `json:"rcpt"`
+		Vars []templateVar `json:"vars"`
+	} `json:"merge_vars"`
+}
+
+// mergeVars returns the global merge vars overridden by those of the recipient
+func (msg *message) mergeVars(email string) []templateVar {
+	var ret []templateVar
+	for _, mv := range msg.MergeVars {
+		if strings.EqualFold(mv.Rcpt, email) {
+			ret = append(ret, mv.Vars...)
+		}
+	}
+	return append(ret, msg.GlobalMergeVars...)
+}
+
+// sendResult is the status of a message sent to one recipient
+type sendResult struct {
+	Email        string `json:"email"`
+	Status       string `json:"status"`
+	RejectReason string `json:"reject_reason,omitempty"`
+	Id           string `json:"_id"`
+}
+
+func messagesSend(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Message json.RawMessage `json:"message"`
+		Async   bool            `json:"async"`
+		SendAt  string          `json:"send_at"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	return s.send(req.Message, nil, nil, req.Async, req.SendAt)
+}
+
+func messagesSendTemplate(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		TemplateName    string          `json:"template_name"`
+		TemplateContent []templateVar   `json:"template_content"`
+		Message         json.RawMessage `json:"message"`
+		Async           bool            `json:"async"`
+		SendAt          string          `json:"send_at"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	t, err := s.template(req.TemplateName)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(req.Message, t, req.TemplateContent, req.Async, req.SendAt)
+}
+
+func messagesSendRaw(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		RawMessage string   `json:"raw_message"`
+		FromEmail  string   `json:"from_email"`
+		FromName   string   `json:"from_name"`
+		To         []string `json:"to"`
+		Async      bool     `json:"async"`
+		SendAt     string   `json:"send_at"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.RawMessage == "" {
+		return nil, errValidation("You must specify a raw_message value")
+	}
+	p, err := parseMIME(req.RawMessage)
+	if err != nil {
+		return nil, err
+	}
+	if req.FromEmail != "" {
+		p.FromEmail = req.FromEmail
+	}
+	if req.FromName != "" {
+		p.FromName = req.FromName
+	}
+	if len(req.To) > 0 {
+		p.To = p.To[:0]
+		for _, to := range req.To {
+			p.To = append(p.To, parsedRecipient{Email: to})
+		}
+	}
+
+	for i, a := range p.Attachments {
+		if !a.Binary {
+			p.Attachments[i].Content = base64.StdEncoding.EncodeToString([]byte(a.Content))
+			p.Attachments[i].Binary = true
+		}
+	}
+
+	// X-MC-* headers set options that would otherwise be given in the message struct
+	msg := struct {
+		*parsedMessage
+		Tags       []string          `json:"tags,omitempty"`
+		SubAccount string            `json:"subaccount,omitempty"`
+		Metadata   map[string]string `json:"metadata,omitempty"`
+	}{parsedMessage: p}
+	for k, v := range p.Headers {
+		switch strings.ToLower(k) {
+		case "x-mc-tags":
+			for _, tag := range strings.Split(v, ",") {
+				msg.Tags = append(msg.Tags, strings.TrimSpace(tag))
+			}
+		case "x-mc-subaccount":
+			msg.SubAccount = strings.TrimSpace(v)
+		case "x-mc-metadata":
+			if err := json.Unmarshal([]byte(v), &msg.Metadata); err != nil {
+				return nil, errValidation("Validation error: X-MC-Metadata must be a JSON object: %s", err)
+			}
+		}
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(raw, nil, nil, req.Async, req.SendAt)
+}
+
+func messagesParse(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		RawMessage string `json:"raw_message"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.RawMessage == "" {
+		return nil, errValidation("You must specify a raw_message value")
+	}
+	return parseMIME(req.RawMessage)
+}
+
+// send records raw as sent to each of its recipients. If t is given its published
+// version, or draft if unpublished, supplies the html and default sender and subject
+func (s *Server) send(raw json.RawMessage, t *template, content []templateVar, async bool, sendAt string) ([]sendResult, error) {
+	var msg *message
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return nil, errValidation("Validation error: %s", err)
+		}
+	}
+	if msg == nil {
+		return nil, errValidation("You must specify a message value")
+	}
+	var slug string
+	if t != nil {
+		fields := t.draft
+		if t.published != nil {
+			fields = *t.published
+		}
+		slug = t.slug
+		msg.HTML = render(fields.Code, content, nil)
+		if msg.FromEmail == "" {
+			msg.FromEmail = fields.FromEmail
+		}
+		if msg.Subject == "" {
+			msg.Subject = fields.Subject
+		}
+	}
+	if msg.FromEmail == "" {
+		return nil, errValidation("Validation error: {\"message\":{\"from_email\":\"Please enter a value\"}}")
+	}
+	if msg.SubAccount != "" && s.subaccounts[msg.SubAccount] == nil {
+		return nil, errUnknownSubaccount(msg.SubAccount)
+	}
+	for _, tag := range msg.Tags {
+		if strings.HasPrefix(tag, "_") {
+			return nil, errValidation("Tags starting with an underscore are reserved for internal use: %s", tag)
+		}
+	}
+
+	var tsend time.Time
+	if sendAt != "" {
+		var err error
+		if tsend, err = time.Parse(timeFormat, sendAt); err != nil {
+			return nil, errValidation("Validation error: {\"send_at\":\"Please enter a valid date\"}")
+		}
+	}
+
+	ret := make([]sendResult, 0, len(msg.To))
+	now := time.Now().UTC()
+	for _, to := range msg.To {
+		state, reason := s.recipientState(to.Email, msg.SubAccount)
+		if state == "" {
+			switch {
+			case !tsend.IsZero() && tsend.After(now):
+				state = "scheduled"
+			case async || len(msg.To) > 10:
+				state = "queued"
+			default:
+				state = "sent"
+			}
+		}
+
+		metadata := make(map[string]string)
+		for k, v := range msg.Metadata {
+			metadata[k] = v
+		}
+		for _, rm := range msg.RecipientMetadata {
+			if strings.EqualFold(rm.Rcpt, to.Email) {
+				for k, v := range rm.Values {
+					metadata[k] = v
+				}
+			}
+		}
+
+		sm := &SentMessage{
+			Id:           s.newId(),
+			TS:           now,
+			Email:        to.Email,
+			Sender:       msg.FromEmail,
+			Subject:      msg.Subject,
+			HTML:         msg.HTML,
+			State:        state,
+			RejectReason: reason,
+			Tags:         msg.Tags,
+			Subaccount:   msg.SubAccount,
+			Template:     slug,
+			Metadata:     metadata,
+			Message:      raw,
+		}
+		if msg.MergeLanguage != "handlebars" {
+			sm.HTML = mergeTag.ReplaceAllStringFunc(msg.HTML, mergeFunc(msg.mergeVars(to.Email)))
+		}
+		if state == "scheduled" {
+			sm.SendAt = tsend
+		}
+		s.messages = append(s.messages, sm)
+		for _, tag := range msg.Tags {
+			delete(s.deletedTags, tag)
+		}
+		ret = append(ret, sendResult{to.Email, state, reason, sm.Id})
+	}
+	return ret, nil
+}
+
+// messageInfo is a sent message as returned by messages/search
+type messageInfo struct {
+	TS           int64             `json:"ts"`
+	Id           string            `json:"_id"`
+	Sender       string            `json:"sender"`
+	Template     string            `json:"template,omitempty"`
+	Subject      string            `json:"subject"`
+	Email        string            `json:"email"`
+	Tags         []string          `json:"tags"`
+	Opens        int               `json:"opens"`
+	OpensDetail  []struct{}        `json:"opens_detail"`
+	Clicks       int               `json:"clicks"`
+	ClicksDetail []struct{}        `json:"clicks_detail"`
+	State        string            `json:"state"`
+	Metadata     map[string]string `json:"metadata"`
+	Subaccount   string            `json:"subaccount,omitempty"`
+	SMTPEvents   []smtpEvent       `json:"smtp_events"`
+}
+
+type smtpEvent struct {
+	TS   int64  `json:"ts"`
+	Type string `json:"type"`
+	Diag string `json:"diag"`
+}
+
+func newMessageInfo(m *SentMessage) messageInfo {
+	info := messageInfo{
+		TS:           m.TS.Unix(),
+		Id:           m.Id,
+		Sender:       m.Sender,
+		Template:     m.Template,
+		Subject:      m.Subject,
+		Email:        m.Email,
+		Tags:         m.Tags,
+		OpensDetail:  []struct{}{},
+		ClicksDetail: []struct{}{},
+		State:        m.State,
+		Metadata:     m.Metadata,
+		Subaccount:   m.Subaccount,
+		SMTPEvents:   []smtpEvent{},
+	}
+	if info.Tags == nil {
+		info.Tags = []string{}
+	}
+	if m.State == "sent" {
+		info.SMTPEvents = append(info.SMTPEvents, smtpEvent{info.TS, "sent", "250 2.0.0 Ok: queued"})
+	}
+	return info
+}
+
+func errUnknownMessage(id string) *apiError {
+	return &apiError{"error", 11, "Unknown_Message", fmt.Sprintf("No message exists with the id '%s'", id)}
+}
+
+// message returns the sent message with id
+func (s *Server) message(id string) (*SentMessage, error) {
+	for _, m := range s.messages {
+		if m.Id == id {
+			return m, nil
+		}
+	}
+	return nil, errUnknownMessage(id)
+}
+
+func messagesInfo(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Id string `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	m, err := s.message(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return newMessageInfo(m), nil
+}
+
+// messageContent is a sent message as returned by messages/content
+type messageContent struct {
+	TS          int64             `json:"ts"`
+	Id          string            `json:"_id"`
+	FromEmail   string            `json:"from_email"`
+	FromName    string            `json:"from_name,omitempty"`
+	Subject     string            `json:"subject"`
+	To          contentRecipient  `json:"to"`
+	Tags        []string          `json:"tags"`
+	Headers     map[string]string `json:"headers"`
+	Text        string            `json:"text,omitempty"`
+	HTML        string            `json:"html,omitempty"`
+	Attachments interface{}       `json:"attachments"`
+}
+
+type contentRecipient struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+func messagesContent(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Id string `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	m, err := s.message(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	var msg message
+	json.Unmarshal(m.Message, &msg)
+
+	ret := messageContent{
+		TS:          m.TS.Unix(),
+		Id:          m.Id,
+		FromEmail:   m.Sender,
+		FromName:    msg.FromName,
+		Subject:     m.Subject,
+		To:          contentRecipient{Email: m.Email},
+		Tags:        m.Tags,
+		Headers:     msg.Headers,
+		Text:        msg.Text,
+		HTML:        m.HTML,
+		Attachments: msg.Attachments,
+	}
+	for _, to := range msg.To {
+		if strings.EqualFold(to.Email, m.Email) {
+			ret.To.Name = to.Name
+		}
+	}
+	if ret.Tags == nil {
+		ret.Tags = []string{}
+	}
+	if ret.Headers == nil {
+		ret.Headers = map[string]string{}
+	}
+	if msg.Attachments == nil {
+		ret.Attachments = []struct{}{}
+	}
+	return ret, nil
+}
+
+// scheduledMessage is a message as returned by the scheduled message endpoints
+type scheduledMessage struct {
+	Id        string `json:"_id"`
+	CreatedAt string `json:"created_at"`
+	SendAt    string `json:"send_at"`
+	FromEmail string `json:"from_email"`
+	To        string `json:"to"`
+	Subject   string `json:"subject"`
+}
+
+func newScheduledMessage(m *SentMessage) scheduledMessage {
+	return scheduledMessage{m.Id, formatTime(m.TS), formatTime(m.SendAt), m.Sender, m.Email, m.Subject}
+}
+
+// scheduled returns the index of the scheduled message with id
+func (s *Server) scheduled(id string) (int, error) {
+	for i, m := range s.messages {
+		if m.Id == id && m.State == "scheduled" {
+			return i, nil
+		}
+	}
+	return 0, errUnknownMessage(id)
+}
+
+func messagesListScheduled(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		To string `json:"to"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	ret := []scheduledMessage{}
+	for _, m := range s.messages {
+		if m.State == "scheduled" && (req.To == "" || strings.EqualFold(m.Email, req.To)) {
+			ret = append(ret, newScheduledMessage(m))
+		}
+	}
+	return ret, nil
+}
+
+func messagesCancelScheduled(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Id string `json:"id"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	i, err := s.scheduled(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	m := s.messages[i]
+	s.messages = append(s.messages[:i], s.messages[i+1:]...)
+	return newScheduledMessage(m), nil
+}
+
+func messagesReschedule(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Id     string `json:"id"`
+		SendAt string `json:"send_at"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	i, err := s.scheduled(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	sendAt, err := time.Parse(timeFormat, req.SendAt)
+	if err != nil {
+		return nil, errValidation("Validation error: {\"send_at\":\"Please enter a valid date\"}")
+	}
+	m := s.messages[i]
+	m.SendAt = sendAt
+	return newScheduledMessage(m), nil
+}
+
+// searchFilter is the filters shared by messages/search and messages/search-time-series
+type searchFilter struct {
+	Query    string   `json:"query"`
+	DateFrom string   `json:"date_from"`
+	DateTo   string   `json:"date_to"`
+	Tags     []string `json:"tags"`
+	Senders  []string `json:"senders"`
+}
+
+// matcher returns a function reporting whether a message matches f
+func (f *searchFilter) matcher() (func(*SentMessage) bool, error) {
+	from, err := searchDate(f.DateFrom, "date_from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := searchDate(f.DateTo, "date_to")
+	if err != nil {
+		return nil, err
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+	terms := queryTerms(f.Query)
+	return func(m *SentMessage) bool {
+		switch {
+		case !from.IsZero() && m.TS.Before(from),
+			!to.IsZero() && !m.TS.Before(to),
+			len(f.Senders) > 0 && !contains(f.Senders, m.Sender),
+			len(f.Tags) > 0 && !anyTag(m, f.Tags):
+			return false
+		}
+		return matchTerms(m, terms)
+	}, nil
+}
+
+func messagesSearch(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		searchFilter
+		APIKeys []string `json:"api_keys"`
+		Limit   *int     `json:"limit"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	limit := 100
+	if req.Limit != nil {
+		limit = *req.Limit
+	}
+	if limit < 0 || limit > 1000 {
+		return nil, errValidation("Validation error: {\"limit\":\"Please enter a number between 0 and 1000\"}")
+	}
+	match, err := req.matcher()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.APIKeys) > 0 && !contains(req.APIKeys, APIKey) {
+		return []messageInfo{}, nil
+	}
+
+	ret := []messageInfo{}
+	for i := len(s.messages) - 1; i >= 0 && len(ret) < limit; i-- {
+		if m := s.messages[i]; match(m) {
+			ret = append(ret, newMessageInfo(m))
+		}
+	}
+	return ret, nil
+}
+
+func messagesSearchTimeSeries(s *Server, body []byte) (interface{}, error) {
+	var req searchFilter
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	match, err := req.matcher()
+	if err != nil {
+		return nil, err
+	}
+	return s.timeSeries(match), nil
+}
+
+// searchDate parses a date parameter given as a date or a UTC date string
+func searchDate(v string, param string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006-01-02", timeFormat} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Truncate(24 * time.Hour), nil
+		}
+	}
+	return time.Time{}, errValidation("Validation error: {\"%s\":\"Please enter a valid date\"}", param)
+}
+
+// queryTerms splits a search query into terms, keeping quoted phrases together
+func queryTerms(q string) []string {
+	var terms []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			if cur.Len() > 0 {
+				terms = append(terms, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		terms = append(terms, cur.String())
+	}
+	return terms
+}
+
+// matchTerms reports whether m matches every term. A term is either "field:value",
+// where field is one of email, sender, subject, state, tags, subaccount, template or
+// u_ followed by a metadata key, or free text matched against the email, sender and subject.
+// Values match case insensitively as substrings, and "*" matches anything
+func matchTerms(m *SentMessage, terms []string) bool {
+	for _, term := range terms {
+		if term == "*" || term == "AND" {
+			continue
+		}
+		field, value := "", term
+		if i := strings.Index(term, ":"); i > 0 {
+			field, value = term[:i], term[i+1:]
+		}
+		var candidates []string
+		switch {
+		case field == "":
+			candidates = []string{m.Email, m.Sender, m.Subject}
+		case field == "email" || field == "full_email":
+			candidates = []string{m.Email}
+		case field == "sender":
+			candidates = []string{m.Sender}
+		case field == "subject":
+			candidates = []string{m.Subject}
+		case field == "state":
+			candidates = []string{m.State}
+		case field == "tags":
+			candidates = m.Tags
+		case field == "subaccount":
+			candidates = []string{m.Subaccount}
+		case field == "template":
+			candidates = []string{m.Template}
+		case strings.HasPrefix(field, "u_"):
+			if v, ok := m.Metadata[field[2:]]; ok {
+				candidates = []string{v}
+			}
+		}
+		if !matchValue(candidates, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchValue(candidates []string, value string) bool {
+	value = strings.ToLower(strings.Trim(value, "*"))
+	for _, c := range candidates {
+		if strings.Contains(strings.ToLower(c), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyTag reports whether m was tagged with any of tags
+func anyTag(m *SentMessage, tags []string) bool {
+	for _, tag := range tags {
+		if hasTag(m, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// recipientState returns "invalid" or "rejected" and a reason if email cannot be sent to.
+// The address reject@test.mandrillapp.com is always rejected unless whitelisted
+func (s *Server) recipientState(email string, subaccount string) (string, string) {
+	if !strings.Contains(email, "@") {
+		return "invalid", ""
+	}
+	email = strings.ToLower(email)
+	if s.whitelists[email] != nil {
+		return "", ""
+	}
+	for _, key := range []string{rejectKey(email, ""), rejectKey(email, subaccount)} {
+		if r := s.rejects[key]; r != nil && !r.expired() {
+			return "rejected", r.reason
+		}
+	}
+	if email == "reject@test.mandrillapp.com" {
+		return "rejected", "hard-bounce"
+	}
+	return "", ""
+}
+
+// stats are the counters reported for tags, senders, urls and the account
+type stats struct {
+	Sent         int `json:"sent"`
+	HardBounces  int `json:"hard_bounces"`
+	SoftBounces  int `json:"soft_bounces"`
+	Rejects      int `json:"rejects"`
+	Complaints   int `json:"complaints"`
+	Unsubs       int `json:"unsubs"`
+	Opens        int `json:"opens"`
+	UniqueOpens  int `json:"unique_opens"`
+	Clicks       int `json:"clicks"`
+	UniqueClicks int `json:"unique_clicks"`
+}
+
+func (st *stats) add(m *SentMessage) {
+	switch m.State {
+	case "sent", "queued":
+		st.Sent++
+	case "rejected":
+		st.Rejects++
+	}
+}
+
+// timeSeriesBucket is an hour of stats
+type timeSeriesBucket struct {
+	Time string `json:"time"`
+	stats
+}
+
+// statsSince returns the stats of messages matching match sent after since
+func (s *Server) statsSince(since time.Time, match func(*SentMessage) bool) stats {
+	var st stats
+	for _, m := range s.messages {
+		if m.TS.After(since) && match(m) {
+			st.add(m)
+		}
+	}
+	return st
+}
+
+// timeSeries returns hourly stats of messages matching match, oldest first
+func (s *Server) timeSeries(match func(*SentMessage) bool) []timeSeriesBucket {
+	ret := []timeSeriesBucket{}
+	index := make(map[time.Time]int)
+	for _, m := range s.messages {
+		if !match(m) {
+			continue
+		}
+		hour := m.TS.Truncate(time.Hour)
+		i, ok := index[hour]
+		if !ok {
+			i = len(ret)
+			index[hour] = i
+			ret = append(ret, timeSeriesBucket{Time: formatTime(hour)})
+		}
+		ret[i].add(m)
+	}
+	return ret
+}
+
+// hasTag reports whether m was tagged with tag
+func hasTag(m *SentMessage, tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
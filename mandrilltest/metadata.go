@@ -0,0 +1,88 @@
+package mandrilltest
+
+import (
+	"sort"
+)
+
+func init() {
+	route("/metadata/list.json", metadataList)
+	route("/metadata/add.json", metadataAdd)
+	route("/metadata/update.json", metadataUpdate)
+	route("/metadata/delete.json", metadataDelete)
+}
+
+// maxMetadataFields is the number of custom metadata fields an account may index
+const maxMetadataFields = 10
+
+type metadataField struct {
+	name         string
+	viewTemplate string
+}
+
+func (f *metadataField) response() map[string]interface{} {
+	return map[string]interface{}{"name": f.name, "state": "active", "view_template": f.viewTemplate}
+}
+
+type metadataRequest struct {
+	Name         string `json:"name"`
+	ViewTemplate string `json:"view_template"`
+}
+
+// metadataArg decodes the metadata parameters and returns the field
+func (s *Server) metadataArg(body []byte) (*metadataField, *metadataRequest, error) {
+	var req metadataRequest
+	if err := decode(body, &req); err != nil {
+		return nil, nil, err
+	}
+	f := s.metadata[req.Name]
+	if f == nil {
+		return nil, nil, &apiError{"error", 11, "Unknown_MetadataField", "No metadata field exists with the name '" + req.Name + "'"}
+	}
+	return f, &req, nil
+}
+
+func metadataList(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, f := range s.metadata {
+		ret = append(ret, f.response())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["name"].(string) < ret[j]["name"].(string) })
+	return ret, nil
+}
+
+func metadataAdd(s *Server, body []byte) (interface{}, error) {
+	var req metadataRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, errValidation("Validation error: {\"name\":\"Please enter a value\"}")
+	}
+	if s.metadata[req.Name] != nil {
+		return nil, errValidation("A metadata field named '%s' already exists", req.Name)
+	}
+	if len(s.metadata) >= maxMetadataFields {
+		return nil, &apiError{"error", 11, "Metadata_FieldLimit", "The maximum number of metadata fields has been reached"}
+	}
+	f := &metadataField{req.Name, req.ViewTemplate}
+	s.metadata[f.name] = f
+	return f.response(), nil
+}
+
+func metadataUpdate(s *Server, body []byte) (interface{}, error) {
+	f, req, err := s.metadataArg(body)
+	if err != nil {
+		return nil, err
+	}
+	f.viewTemplate = req.ViewTemplate
+	return f.response(), nil
+}
+
+func metadataDelete(s *Server, body []byte) (interface{}, error) {
+	f, _, err := s.metadataArg(body)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.metadata, f.name)
+	return f.response(), nil
+}
@@ -0,0 +1,136 @@
+package mandrilltest
+
+import (
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+)
+
+// parsedMessage is a raw MIME message as returned by messages/parse
+type parsedMessage struct {
+	Subject     string            `json:"subject"`
+	FromEmail   string            `json:"from_email"`
+	FromName    string            `json:"from_name,omitempty"`
+	To          []parsedRecipient `json:"to"`
+	Headers     map[string]string `json:"headers"`
+	Text        string            `json:"text,omitempty"`
+	HTML        string            `json:"html,omitempty"`
+	Attachments []parsedPart      `json:"attachments"`
+	Images      []parsedPart      `json:"images"`
+}
+
+type parsedRecipient struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+type parsedPart struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// parseMIME parses raw as an RFC 5322 message
+func parseMIME(raw string) (*parsedMessage, error) {
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		return nil, errValidation("Validation error: {\"raw_message\":\"Please enter a valid MIME message: %s\"}", err)
+	}
+	dec := new(mime.WordDecoder)
+	ret := &parsedMessage{
+		Headers:     make(map[string]string),
+		To:          []parsedRecipient{},
+		Attachments: []parsedPart{},
+		Images:      []parsedPart{},
+	}
+	for k, v := range msg.Header {
+		ret.Headers[k] = strings.Join(v, ", ")
+	}
+	if ret.Subject, err = dec.DecodeHeader(msg.Header.Get("Subject")); err != nil {
+		ret.Subject = msg.Header.Get("Subject")
+	}
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		ret.FromEmail, ret.FromName = from.Address, from.Name
+	}
+	for _, field := range []string{"To", "Cc", "Bcc"} {
+		list, err := msg.Header.AddressList(field)
+		if err != nil {
+			continue
+		}
+		for _, a := range list {
+			ret.To = append(ret.To, parsedRecipient{a.Address, a.Name, strings.ToLower(field)})
+		}
+	}
+	if err := ret.addPart(mimeHeader(msg.Header), msg.Body); err != nil {
+		return nil, errValidation("Validation error: {\"raw_message\":\"Please enter a valid MIME message: %s\"}", err)
+	}
+	return ret, nil
+}
+
+// mimeHeader is the subset of headers used to parse a part
+type mimeHeader interface {
+	Get(key string) string
+}
+
+// addPart adds the content of a part, recursing into multipart parts
+func (p *parsedMessage) addPart(h mimeHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := p.addPart(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	name := dparams["filename"]
+	if name == "" {
+		name = params["name"]
+	}
+	cid := strings.Trim(h.Get("Content-Id"), "<>")
+	switch {
+	case strings.HasPrefix(mediaType, "image/") && cid != "" && disposition != "attachment":
+		p.Images = append(p.Images, parsedPart{cid, mediaType, base64.StdEncoding.EncodeToString(b), true})
+	case disposition == "attachment" || name != "":
+		if strings.HasPrefix(mediaType, "text/") {
+			p.Attachments = append(p.Attachments, parsedPart{name, mediaType, string(b), false})
+		} else {
+			p.Attachments = append(p.Attachments, parsedPart{name, mediaType, base64.StdEncoding.EncodeToString(b), true})
+		}
+	case mediaType == "text/html" && p.HTML == "":
+		p.HTML = string(b)
+	case mediaType == "text/plain" && p.Text == "":
+		p.Text = string(b)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package mandrilltest
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/rejects/add.json", rejectsAdd)
+	route("/rejects/delete.json", rejectsDelete)
+	route("/rejects/list.json", rejectsList)
+}
+
+// reject is a blacklist entry
+type reject struct {
+	email      string
+	reason     string
+	detail     string
+	subaccount string
+	createdAt  time.Time
+	expiresAt  time.Time
+}
+
+func (r *reject) expired() bool {
+	return !r.expiresAt.IsZero() && r.expiresAt.Before(time.Now())
+}
+
+// rejectKey identifies a blacklist entry, which is scoped to a subaccount if given
+func rejectKey(email string, subaccount string) string {
+	return strings.ToLower(email) + "\x00" + subaccount
+}
+
+func rejectsAdd(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Email      string `json:"email"`
+		Comment    string `json:"comment"`
+		Subaccount string `json:"subaccount"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(req.Email, "@") {
+		return nil, errValidation("Validation error: {\"email\":\"An email address must contain a single @\"}")
+	}
+	if req.Subaccount != "" && s.subaccounts[req.Subaccount] == nil {
+		return nil, errUnknownSubaccount(req.Subaccount)
+	}
+	s.rejects[rejectKey(req.Email, req.Subaccount)] = &reject{
+		email:      req.Email,
+		reason:     "custom",
+		detail:     req.Comment,
+		subaccount: req.Subaccount,
+		createdAt:  time.Now().UTC(),
+	}
+	return map[string]interface{}{"email": req.Email, "added": true}, nil
+}
+
+func rejectsDelete(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Email      string `json:"email"`
+		Subaccount string `json:"subaccount"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	key := rejectKey(req.Email, req.Subaccount)
+	_, deleted := s.rejects[key]
+	delete(s.rejects, key)
+	return map[string]interface{}{"email": req.Email, "deleted": deleted, "subaccount": req.Subaccount}, nil
+}
+
+func rejectsList(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Email          string `json:"email"`
+		IncludeExpired bool   `json:"include_expired"`
+		Subaccount     string `json:"subaccount"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	ret := []map[string]interface{}{}
+	for _, r := range s.rejects {
+		if req.Email != "" && !strings.EqualFold(req.Email, r.email) {
+			continue
+		}
+		if req.Subaccount != "" && req.Subaccount != r.subaccount {
+			continue
+		}
+		if r.expired() && !req.IncludeExpired {
+			continue
+		}
+		ret = append(ret, map[string]interface{}{
+			"email":         r.email,
+			"reason":        r.reason,
+			"detail":        r.detail,
+			"created_at":    formatTime(r.createdAt),
+			"last_event_at": formatTime(r.createdAt),
+			"expires_at":    formatTime(r.expiresAt),
+			"expired":       r.expired(),
+			"subaccount":    r.subaccount,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["email"].(string) < ret[j]["email"].(string) })
+	return ret, nil
+}
@@ -0,0 +1,152 @@
+package mandrilltest
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/senders/list.json", sendersList)
+	route("/senders/domains.json", sendersDomains)
+	route("/senders/add-domain.json", sendersAddDomain)
+	route("/senders/check-domain.json", sendersCheckDomain)
+	route("/senders/verify-domain.json", sendersVerifyDomain)
+	route("/senders/info.json", sendersInfo)
+	route("/senders/time-series.json", sendersTimeSeries)
+}
+
+// senderDomain is a sending domain added to the account. Domains are
+// reported as having valid SPF and DKIM records
+type senderDomain struct {
+	domain     string
+	createdAt  time.Time
+	verifiedAt time.Time
+}
+
+func (d *senderDomain) response() map[string]interface{} {
+	now := formatTime(time.Now())
+	return map[string]interface{}{
+		"domain":         d.domain,
+		"created_at":     formatTime(d.createdAt),
+		"last_tested_at": now,
+		"spf":            map[string]interface{}{"valid": true, "valid_after": now, "error": ""},
+		"dkim":           map[string]interface{}{"valid": true, "valid_after": now, "error": ""},
+		"verified_at":    formatTime(d.verifiedAt),
+		"valid_signing":  true,
+	}
+}
+
+// senderDomainArg decodes the domain parameter, adding the domain if it has not been seen
+func (s *Server) senderDomainArg(body []byte) (*senderDomain, error) {
+	var req struct {
+		Domain string `json:"domain"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Domain == "" {
+		return nil, errValidation("Validation error: {\"domain\":\"Please enter a value\"}")
+	}
+	key := strings.ToLower(req.Domain)
+	d := s.senderDomains[key]
+	if d == nil {
+		d = &senderDomain{domain: req.Domain, createdAt: time.Now().UTC()}
+		s.senderDomains[key] = d
+	}
+	return d, nil
+}
+
+func sendersList(s *Server, body []byte) (interface{}, error) {
+	return s.senders(), nil
+}
+
+func sendersDomains(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, d := range s.senderDomains {
+		ret = append(ret, d.response())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["domain"].(string) < ret[j]["domain"].(string) })
+	return ret, nil
+}
+
+func sendersAddDomain(s *Server, body []byte) (interface{}, error) {
+	d, err := s.senderDomainArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return d.response(), nil
+}
+
+func sendersCheckDomain(s *Server, body []byte) (interface{}, error) {
+	d, err := s.senderDomainArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return d.response(), nil
+}
+
+func sendersVerifyDomain(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Mailbox string `json:"mailbox"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	d, err := s.senderDomainArg(body)
+	if err != nil {
+		return nil, err
+	}
+	if d.verifiedAt.IsZero() {
+		d.verifiedAt = time.Now().UTC()
+	}
+	return map[string]interface{}{
+		"status": "sent",
+		"domain": d.domain,
+		"email":  req.Mailbox + "@" + d.domain,
+	}, nil
+}
+
+// senderArg decodes the address parameter, returning an error if it has not sent a message
+func (s *Server) senderArg(body []byte) (senderInfo, error) {
+	var req struct {
+		Address string `json:"address"`
+	}
+	if err := decode(body, &req); err != nil {
+		return senderInfo{}, err
+	}
+	for _, sender := range s.senders() {
+		if strings.EqualFold(sender.Address, req.Address) {
+			return sender, nil
+		}
+	}
+	return senderInfo{}, &apiError{"error", 4, "Unknown_Sender", "No sender exists with the address '" + req.Address + "'"}
+}
+
+func sendersInfo(s *Server, body []byte) (interface{}, error) {
+	sender, err := s.senderArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"address":      sender.Address,
+		"created_at":   sender.CreatedAt,
+		"sent":         sender.Sent,
+		"hard_bounces": sender.HardBounces,
+		"soft_bounces": sender.SoftBounces,
+		"rejects":      sender.Rejects,
+		"complaints":   sender.Complaints,
+		"unsubs":       sender.Unsubs,
+		"opens":        sender.Opens,
+		"clicks":       sender.Clicks,
+		"stats":        s.periodStats(func(m *SentMessage) bool { return m.Sender == sender.Address }),
+	}, nil
+}
+
+func sendersTimeSeries(s *Server, body []byte) (interface{}, error) {
+	sender, err := s.senderArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return s.timeSeries(func(m *SentMessage) bool { return m.Sender == sender.Address }), nil
+}
@@ -0,0 +1,290 @@
+// Package mandrilltest provides an in-memory Mandrill api server for tests.
+//
+// A Server implements the endpoints covered by the mandrill package, keeps state
+// between calls, returns Mandrill style error responses and can inject faults
+//
+//	s := mandrilltest.NewServer()
+//	defer s.Close()
+//	m := mandrill.NewMandrill(mandrilltest.APIKey, mandrill.WithBaseURL(s.URL))
+//
+// An SMTPServer stands in for the SMTP relay
+package mandrilltest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKey is the only API key accepted by a Server
+const APIKey = "mandrilltest-api-key"
+
+// DefaultHourlyQuota is the hourly quota reported for the account and subaccounts without a custom quota
+const DefaultHourlyQuota = 250
+
+// timeFormat is the format of UTC date strings used by the api
+const timeFormat = "2006-01-02 15:04:05"
+
+// Server is an in-memory Mandrill api server. It is safe for concurrent use
+type Server struct {
+	*httptest.Server
+
+	// HourlyQuota and Backlog are reported by users/info
+	HourlyQuota int
+	Backlog     int
+
+	mu       sync.Mutex
+	faults   []*Fault
+	requests []Request
+	nextId   int
+
+	messages        []*SentMessage
+	rejects         map[string]*reject
+	whitelists      map[string]*whitelist
+	templates       map[string]*template
+	webhooks        map[int]*webhook
+	subaccounts     map[string]*subaccount
+	inboundDomains  map[string]*inboundDomain
+	inboundRoutes   map[string]*inboundRoute
+	exports         map[string]*export
+	ips             map[string]*ip
+	pools           map[string]*pool
+	metadata        map[string]*metadataField
+	senderDomains   map[string]*senderDomain
+	trackingDomains map[string]*trackingDomain
+	deletedTags     map[string]bool
+}
+
+// Request records a call made to the server
+type Request struct {
+	// the api path, e.g. "/messages/send.json"
+	Path string
+
+	// the JSON request body
+	Body json.RawMessage
+}
+
+// Fault describes a failure to inject into requests
+type Fault struct {
+	// the api path to affect, e.g. "/messages/send.json". Empty affects every path
+	Path string
+
+	// the number of requests to affect. Zero affects every request until cleared
+	Times int
+
+	// a delay before the request is handled
+	Latency time.Duration
+
+	// an HTTP status to respond with, with a non-JSON body, instead of handling the request
+	Status int
+
+	// whether to handle the request but truncate the JSON response body
+	Malformed bool
+}
+
+// handler implements an api endpoint. The returned value is encoded as the JSON response
+type handler func(s *Server, body []byte) (interface{}, error)
+
+var routes = map[string]handler{}
+
+// route registers h for path. Called from init in each endpoint file
+func route(path string, h handler) {
+	routes[path] = h
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when finished
+func NewServer() *Server {
+	s := &Server{
+		HourlyQuota:     DefaultHourlyQuota,
+		rejects:         make(map[string]*reject),
+		whitelists:      make(map[string]*whitelist),
+		templates:       make(map[string]*template),
+		webhooks:        make(map[int]*webhook),
+		subaccounts:     make(map[string]*subaccount),
+		inboundDomains:  make(map[string]*inboundDomain),
+		inboundRoutes:   make(map[string]*inboundRoute),
+		exports:         make(map[string]*export),
+		ips:             make(map[string]*ip),
+		pools:           map[string]*pool{defaultPool: {name: defaultPool, createdAt: time.Now().UTC()}},
+		metadata:        make(map[string]*metadataField),
+		senderDomains:   make(map[string]*senderDomain),
+		trackingDomains: make(map[string]*trackingDomain),
+		deletedTags:     make(map[string]bool),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddFault injects f into subsequent requests. Faults are checked in the order added
+func (s *Server) AddFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests received so far, including those affected by faults
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	path := "/" + strings.TrimPrefix(r.URL.Path, "/")
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: path, Body: body})
+	f := s.takeFault(path)
+	s.mu.Unlock()
+
+	if f != nil && f.Latency > 0 {
+		if err := sleepContext(r.Context(), f.Latency); err != nil {
+			return
+		}
+	}
+	if f != nil && f.Status != 0 {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(f.Status)
+		fmt.Fprintf(w, "<html><body><h1>%d %s</h1></body></html>", f.Status, http.StatusText(f.Status))
+		return
+	}
+
+	resp, err := s.handle(path, body)
+	var respB []byte
+	status := http.StatusOK
+	if err != nil {
+		ae, ok := err.(*apiError)
+		if !ok {
+			ae = errGeneral(err.Error())
+		}
+		status = http.StatusInternalServerError
+		respB, _ = json.Marshal(ae)
+	} else if respB, err = json.Marshal(resp); err != nil {
+		status = http.StatusInternalServerError
+		respB, _ = json.Marshal(errGeneral(err.Error()))
+	}
+
+	if f != nil && f.Malformed {
+		respB = respB[:len(respB)/2]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(respB)
+}
+
+// handle authenticates the request and dispatches it to the endpoint for path
+func (s *Server) handle(path string, body []byte) (interface{}, error) {
+	h, ok := routes[path]
+	if !ok {
+		return nil, &apiError{"error", -1, "Invalid_Method", fmt.Sprintf("Unknown method %q", path)}
+	}
+
+	var auth struct {
+		Key *string `json:"key"`
+	}
+	if err := json.Unmarshal(body, &auth); err != nil {
+		return nil, errValidation("You must specify a key value")
+	}
+	if auth.Key == nil {
+		return nil, errValidation("You must specify a key value")
+	}
+	if *auth.Key != APIKey {
+		return nil, &apiError{"error", -1, "Invalid_Key", "Invalid API key"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return h(s, body)
+}
+
+// takeFault returns the first fault matching path, consuming one of its uses
+func (s *Server) takeFault(path string) *Fault {
+	for i, f := range s.faults {
+		if f.Path != "" && f.Path != path {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// newId returns a new unique identifier
+func (s *Server) newId() string {
+	s.nextId++
+	return fmt.Sprintf("%032x", s.nextId)
+}
+
+// apiError is an error response in the format returned by Mandrill
+type apiError struct {
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+func (a *apiError) Error() string {
+	return a.Name + ": " + a.Message
+}
+
+func errValidation(format string, args ...interface{}) *apiError {
+	return &apiError{"error", -2, "ValidationError", fmt.Sprintf(format, args...)}
+}
+
+func errGeneral(msg string) *apiError {
+	return &apiError{"error", -1, "GeneralError", msg}
+}
+
+// decode unmarshals the request body into v, reporting failures as validation errors
+func decode(body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return errValidation("Validation error: %s", err)
+	}
+	return nil
+}
+
+// formatTime returns t as a UTC date string, or an empty string if t is zero
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeFormat)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package mandrilltest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/mandrill"
+	"github.com/jimtsao/mandrill/mandrilltest"
+)
+
+func TestInvalidKey(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+
+	m := mandrill.NewMandrill("bad-key", mandrill.WithBaseURL(s.URL))
+	if _, err := m.Users().Ping(); !errors.Is(err, mandrill.ErrInvalidKey) {
+		t.Errorf("expected invalid key error. Received: %v", err)
+	}
+}
+
+func TestSendStates(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+
+	m := mandrill.NewMandrill(mandrilltest.APIKey, mandrill.WithBaseURL(s.URL))
+	if _, err := m.Rejects().Add("blocked@example.com", "test", ""); err != nil {
+		t.Error(err)
+		return
+	}
+	msg := &mandrill.Message{
+		FromEmail: "sender@example.com",
+		To: []mandrill.Recipient{
+			{Email: "jane@example.com"},
+			{Email: "blocked@example.com"},
+			{Email: "not-an-address"},
+		},
+		Tags: []string{"welcome"},
+	}
+	rr, err := m.Messages().Send(msg, false, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []string{"sent", "rejected", "invalid"}
+	if len(rr) != len(expected) {
+		t.Errorf("expected %d responses. Received: %+v", len(expected), rr)
+		return
+	}
+	for i, r := range rr {
+		if r.Status != expected[i] || r.Id == "" {
+			t.Errorf("expected status %s. Received: %+v", expected[i], r)
+		}
+	}
+
+	if sent := s.Messages(); len(sent) != 3 || sent[0].Email != "jane@example.com" || sent[0].Sender != "sender@example.com" {
+		t.Errorf("expected recorded messages. Received: %+v", sent)
+	}
+	if info, err := m.Tags().Info("welcome"); err != nil || info.Tag != "welcome" || info.Sent != 1 || info.Rejects != 1 {
+		t.Errorf("expected tag stats. Received: %+v, %v", info, err)
+	}
+
+	msg.SubAccount = "unknown"
+	if _, err := m.Messages().Send(msg, false, "", nil); !errors.Is(err, mandrill.ErrUnknownSubaccount) {
+		t.Errorf("expected unknown subaccount error. Received: %v", err)
+	}
+}
+
+func TestFaultStatus(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+
+	s.AddFault(mandrilltest.Fault{Path: "/users/ping.json", Times: 1, Status: 503})
+	m := mandrill.NewMandrill(mandrilltest.APIKey, mandrill.WithBaseURL(s.URL))
+	var de *mandrill.DecodeError
+	if _, err := m.Users().Ping(); !errors.As(err, &de) || de.HTTPStatus != 503 {
+		t.Errorf("expected 503 decode error. Received: %v", err)
+	}
+	if ok, err := m.Users().Ping(); err != nil || !ok {
+		t.Errorf("expected fault to be used up. Received: %v", err)
+	}
+	if n := len(s.Requests()); n != 2 {
+		t.Errorf("expected 2 requests. Received: %d", n)
+	}
+}
+
+func TestFaultMalformed(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+
+	s.AddFault(mandrilltest.Fault{Malformed: true})
+	m := mandrill.NewMandrill(mandrilltest.APIKey, mandrill.WithBaseURL(s.URL))
+	if _, err := m.Users().Info(); err == nil {
+		t.Error("expected malformed response error")
+	}
+
+	s.ClearFaults()
+	if _, err := m.Users().Info(); err != nil {
+		t.Errorf("expected faults to be cleared. Received: %v", err)
+	}
+}
+
+func TestFaultLatency(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+
+	s.AddFault(mandrilltest.Fault{Latency: time.Second})
+	m := mandrill.NewMandrill(mandrilltest.APIKey, mandrill.WithBaseURL(s.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := m.Users().PingContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded. Received: %v", err)
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+
+	m := mandrill.NewMandrill(mandrilltest.APIKey, mandrill.WithBaseURL(s.URL))
+	tpl := &mandrill.Template{Name: "Welcome Email", Code: `<h1 mc:edit="header">Default</h1><p>Hi *|FNAME|*</p>`}
+	if resp, err := m.Templates().Add(tpl); err != nil || resp.Slug != "welcome-email" {
+		t.Errorf("expected template to be added. Received: %+v, %v", resp, err)
+		return
+	}
+	if _, err := m.Templates().Add(tpl); !errors.Is(err, mandrill.ErrInvalidTemplate) {
+		t.Errorf("expected duplicate template error. Received: %v", err)
+	}
+
+	html, err := m.Templates().Render(&mandrill.TemplatesRenderRequest{
+		TemplateName:    "welcome-email",
+		TemplateContent: []mandrill.TemplateMergeVar{{Name: "header", Content: "Welcome"}},
+		MergeVars:       []mandrill.TemplateMergeVar{{Name: "fname", Content: "Jane"}},
+	})
+	if err != nil || html != "<h1>Welcome</h1><p>Hi Jane</p>" {
+		t.Errorf("expected rendered template. Received: %s, %v", html, err)
+	}
+}
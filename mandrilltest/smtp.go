@@ -0,0 +1,322 @@
+package mandrilltest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMTPServer is an in-process stand-in for the Mandrill SMTP relay. It supports STARTTLS
+// with a self-signed certificate and AUTH PLAIN and LOGIN with APIKey as the password, and
+// records the messages it accepts. It is safe for concurrent use
+type SMTPServer struct {
+	// Addr is the host:port the server listens on
+	Addr string
+
+	ln        net.Listener
+	tlsConfig *tls.Config
+	roots     *x509.CertPool
+	wg        sync.WaitGroup
+
+	mu    sync.Mutex
+	mails []Mail
+	conns map[net.Conn]bool
+	noTLS bool
+}
+
+// Mail is a message accepted by an SMTPServer
+type Mail struct {
+	// the envelope sender and recipients
+	From string
+	To   []string
+
+	// the message as sent, headers included
+	Data []byte
+
+	// the authenticated user name
+	Username string
+
+	// whether the message was sent after STARTTLS
+	TLS bool
+}
+
+// NewSMTPServer starts and returns a new SMTPServer listening on a local port.
+// The caller should call Close when finished
+func NewSMTPServer() *SMTPServer {
+	cert, roots := selfSignedCert()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("mandrilltest: failed to listen: " + err.Error())
+	}
+	s := &SMTPServer{
+		Addr:      ln.Addr().String(),
+		ln:        ln,
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		roots:     roots,
+		conns:     make(map[net.Conn]bool),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// ClientTLSConfig returns a TLS config trusting the server's certificate
+func (s *SMTPServer) ClientTLSConfig() *tls.Config {
+	return &tls.Config{RootCAs: s.roots, ServerName: "127.0.0.1"}
+}
+
+// SetStartTLS sets whether the server advertises and accepts STARTTLS, which it does by default.
+// It applies to commands received after the call
+func (s *SMTPServer) SetStartTLS(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.noTLS = !enabled
+}
+
+func (s *SMTPServer) startTLS() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.noTLS
+}
+
+// Mails returns the messages accepted so far
+func (s *SMTPServer) Mails() []Mail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Mail(nil), s.mails...)
+}
+
+// Close stops the server and closes open connections
+func (s *SMTPServer) Close() {
+	s.ln.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *SMTPServer) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[c] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.session(c)
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+			c.Close()
+		}()
+	}
+}
+
+// session handles the SMTP conversation on c
+func (s *SMTPServer) session(c net.Conn) {
+	tc := textproto.NewConn(c)
+	isTLS := false
+	var username string
+	var authed bool
+	var mail *Mail
+	reply := func(code int, msg string) {
+		tc.PrintfLine("%d %s", code, msg)
+	}
+
+	reply(220, "mandrilltest ESMTP")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg := line, ""
+		if i := strings.Index(line, " "); i >= 0 {
+			verb, arg = line[:i], line[i+1:]
+		}
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			reply(250, "mandrilltest")
+		case "EHLO":
+			lines := []string{"mandrilltest", "8BITMIME", "AUTH PLAIN LOGIN"}
+			if !isTLS && s.startTLS() {
+				lines = append(lines, "STARTTLS")
+			}
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				tc.PrintfLine("250%s%s", sep, l)
+			}
+		case "STARTTLS":
+			if isTLS || !s.startTLS() {
+				reply(502, "STARTTLS not available")
+				continue
+			}
+			reply(220, "Ready to start TLS")
+			tlsConn := tls.Server(c, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			c, tc, isTLS = tlsConn, textproto.NewConn(tlsConn), true
+			username, authed, mail = "", false, nil
+		case "AUTH":
+			user, ok := s.auth(tc, arg)
+			if !ok {
+				reply(535, "Authentication failed")
+				continue
+			}
+			username, authed = user, true
+			reply(235, "Authentication successful")
+		case "MAIL":
+			if !authed {
+				reply(530, "Authentication required")
+				continue
+			}
+			mail = &Mail{From: address(arg), Username: username, TLS: isTLS}
+			reply(250, "OK")
+		case "RCPT":
+			if mail == nil {
+				reply(503, "Need MAIL command")
+				continue
+			}
+			to := address(arg)
+			if !strings.Contains(to, "@") {
+				reply(550, "Invalid recipient")
+				continue
+			}
+			mail.To = append(mail.To, to)
+			reply(250, "OK")
+		case "DATA":
+			if mail == nil || len(mail.To) == 0 {
+				reply(503, "Need RCPT command")
+				continue
+			}
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+			data, err := tc.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.Data = data
+			s.mu.Lock()
+			s.mails = append(s.mails, *mail)
+			s.mu.Unlock()
+			mail = nil
+			reply(250, "OK: queued")
+		case "RSET":
+			mail = nil
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+// auth runs an AUTH exchange, returning the user name if the password is APIKey
+func (s *SMTPServer) auth(tc *textproto.Conn, arg string) (string, bool) {
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		return "", false
+	}
+	challenge := func(prompt string) (string, bool) {
+		tc.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte(prompt)))
+		line, err := tc.ReadLine()
+		if err != nil {
+			return "", false
+		}
+		b, err := base64.StdEncoding.DecodeString(line)
+		return string(b), err == nil
+	}
+
+	switch strings.ToUpper(fields[0]) {
+	case "PLAIN":
+		var resp string
+		if len(fields) > 1 {
+			b, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return "", false
+			}
+			resp = string(b)
+		} else {
+			var ok bool
+			if resp, ok = challenge(""); !ok {
+				return "", false
+			}
+		}
+		parts := strings.Split(resp, "\x00")
+		if len(parts) != 3 || parts[2] != APIKey {
+			return "", false
+		}
+		return parts[1], true
+	case "LOGIN":
+		user, ok := challenge("Username:")
+		if !ok {
+			return "", false
+		}
+		pass, ok := challenge("Password:")
+		if !ok || pass != APIKey {
+			return "", false
+		}
+		return user, true
+	}
+	return "", false
+}
+
+// address returns the address in a MAIL FROM:<addr> or RCPT TO:<addr> argument
+func address(arg string) string {
+	start, end := strings.Index(arg, "<"), strings.Index(arg, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return arg[start+1 : end]
+}
+
+// selfSignedCert returns a certificate for 127.0.0.1 and localhost, and a pool trusting it
+func selfSignedCert() (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic("mandrilltest: failed to generate key: " + err.Error())
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"mandrilltest"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		panic("mandrilltest: failed to create certificate: " + err.Error())
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, roots
+}
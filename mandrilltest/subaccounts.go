@@ -0,0 +1,176 @@
+package mandrilltest
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/subaccounts/list.json", subaccountsList)
+	route("/subaccounts/add.json", subaccountsAdd)
+	route("/subaccounts/info.json", subaccountsInfo)
+	route("/subaccounts/update.json", subaccountsUpdate)
+	route("/subaccounts/delete.json", subaccountsDelete)
+	route("/subaccounts/pause.json", subaccountsPause)
+	route("/subaccounts/resume.json", subaccountsResume)
+}
+
+type subaccount struct {
+	id          string
+	name        string
+	notes       string
+	customQuota int
+	status      string
+	createdAt   time.Time
+}
+
+// sentSince returns the number of messages sent by sub after t
+func (s *Server) sentSince(sub *subaccount, t time.Time) int {
+	return s.statsSince(t, func(m *SentMessage) bool { return m.Subaccount == sub.id }).Sent
+}
+
+func (s *Server) subaccountResponse(sub *subaccount) map[string]interface{} {
+	now := time.Now().UTC()
+	var firstSentAt time.Time
+	for _, m := range s.messages {
+		if m.Subaccount == sub.id {
+			firstSentAt = m.TS
+			break
+		}
+	}
+	weekStart := now.Truncate(24*time.Hour).AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return map[string]interface{}{
+		"id":            sub.id,
+		"name":          sub.name,
+		"custom_quota":  sub.customQuota,
+		"status":        sub.status,
+		"reputation":    75,
+		"created_at":    formatTime(sub.createdAt),
+		"first_sent_at": formatTime(firstSentAt),
+		"sent_weekly":   s.sentSince(sub, weekStart),
+		"sent_monthly":  s.sentSince(sub, monthStart),
+		"sent_total":    s.sentSince(sub, time.Time{}),
+	}
+}
+
+// subaccountRequest holds the parameters of the subaccount endpoints
+type subaccountRequest struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Notes       string `json:"notes"`
+	CustomQuota int    `json:"custom_quota"`
+}
+
+func errUnknownSubaccount(id string) *apiError {
+	return &apiError{"error", 12, "Unknown_Subaccount", "No subaccount exists with the id '" + id + "'"}
+}
+
+// subaccountArg decodes the subaccount parameters and returns the subaccount
+func (s *Server) subaccountArg(body []byte) (*subaccount, *subaccountRequest, error) {
+	var req subaccountRequest
+	if err := decode(body, &req); err != nil {
+		return nil, nil, err
+	}
+	sub := s.subaccounts[req.Id]
+	if sub == nil {
+		return nil, nil, errUnknownSubaccount(req.Id)
+	}
+	return sub, &req, nil
+}
+
+func subaccountsList(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Q string `json:"q"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	ret := []map[string]interface{}{}
+	for _, sub := range s.subaccounts {
+		if strings.HasPrefix(sub.id, req.Q) || strings.HasPrefix(sub.name, req.Q) {
+			ret = append(ret, s.subaccountResponse(sub))
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["id"].(string) < ret[j]["id"].(string) })
+	return ret, nil
+}
+
+func subaccountsAdd(s *Server, body []byte) (interface{}, error) {
+	var req subaccountRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Id == "" || len(req.Id) > 255 {
+		return nil, errValidation("Validation error: {\"id\":\"Please enter a value no longer than 255 characters\"}")
+	}
+	if s.subaccounts[req.Id] != nil {
+		return nil, errValidation("A subaccount with id '%s' already exists", req.Id)
+	}
+	sub := &subaccount{
+		id:          req.Id,
+		name:        req.Name,
+		notes:       req.Notes,
+		customQuota: req.CustomQuota,
+		status:      "active",
+		createdAt:   time.Now().UTC(),
+	}
+	s.subaccounts[sub.id] = sub
+	return s.subaccountResponse(sub), nil
+}
+
+func subaccountsInfo(s *Server, body []byte) (interface{}, error) {
+	sub, _, err := s.subaccountArg(body)
+	if err != nil {
+		return nil, err
+	}
+	quota := sub.customQuota
+	if quota == 0 {
+		quota = DefaultHourlyQuota
+	}
+	ret := s.subaccountResponse(sub)
+	ret["notes"] = sub.notes
+	ret["hourly_quota"] = quota
+	ret["sent_hourly"] = s.sentSince(sub, time.Now().Add(-time.Hour))
+	ret["last_30_days"] = s.statsSince(time.Now().AddDate(0, 0, -30), func(m *SentMessage) bool { return m.Subaccount == sub.id })
+	return ret, nil
+}
+
+func subaccountsUpdate(s *Server, body []byte) (interface{}, error) {
+	sub, req, err := s.subaccountArg(body)
+	if err != nil {
+		return nil, err
+	}
+	sub.name = req.Name
+	sub.notes = req.Notes
+	sub.customQuota = req.CustomQuota
+	return s.subaccountResponse(sub), nil
+}
+
+func subaccountsDelete(s *Server, body []byte) (interface{}, error) {
+	sub, _, err := s.subaccountArg(body)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.subaccounts, sub.id)
+	return s.subaccountResponse(sub), nil
+}
+
+func subaccountsPause(s *Server, body []byte) (interface{}, error) {
+	sub, _, err := s.subaccountArg(body)
+	if err != nil {
+		return nil, err
+	}
+	sub.status = "paused"
+	return s.subaccountResponse(sub), nil
+}
+
+func subaccountsResume(s *Server, body []byte) (interface{}, error) {
+	sub, _, err := s.subaccountArg(body)
+	if err != nil {
+		return nil, err
+	}
+	sub.status = "active"
+	return s.subaccountResponse(sub), nil
+}
@@ -0,0 +1,107 @@
+package mandrilltest
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/tags/list.json", tagsList)
+	route("/tags/delete.json", tagsDelete)
+	route("/tags/info.json", tagsInfo)
+	route("/tags/time-series.json", tagsTimeSeries)
+	route("/tags/all-time-series.json", tagsAllTimeSeries)
+}
+
+// tagInfo is a tag with its stats
+type tagInfo struct {
+	Tag        string `json:"tag"`
+	Reputation int    `json:"reputation"`
+	stats
+}
+
+// tags returns the tags used by sent messages that have not been deleted, ordered by name
+func (s *Server) tags() []string {
+	seen := make(map[string]bool)
+	var ret []string
+	for _, m := range s.messages {
+		for _, t := range m.Tags {
+			if !seen[t] && !s.deletedTags[t] {
+				seen[t] = true
+				ret = append(ret, t)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// tagArg decodes the tag parameter, returning an error if it has not been used
+func (s *Server) tagArg(body []byte) (string, error) {
+	var req struct {
+		Tag string `json:"tag"`
+	}
+	if err := decode(body, &req); err != nil {
+		return "", err
+	}
+	for _, t := range s.tags() {
+		if t == req.Tag {
+			return t, nil
+		}
+	}
+	return "", &apiError{"error", 3, "Invalid_Tag_Name", "No tag exists with the name '" + req.Tag + "'"}
+}
+
+func (s *Server) tagInfo(tag string) tagInfo {
+	st := s.statsSince(time.Time{}, func(m *SentMessage) bool { return hasTag(m, tag) })
+	return tagInfo{Tag: tag, Reputation: 75, stats: st}
+}
+
+func tagsList(s *Server, body []byte) (interface{}, error) {
+	ret := []tagInfo{}
+	for _, t := range s.tags() {
+		ret = append(ret, s.tagInfo(t))
+	}
+	return ret, nil
+}
+
+func tagsDelete(s *Server, body []byte) (interface{}, error) {
+	tag, err := s.tagArg(body)
+	if err != nil {
+		return nil, err
+	}
+	ret := s.tagInfo(tag)
+	s.deletedTags[tag] = true
+	return ret, nil
+}
+
+func tagsInfo(s *Server, body []byte) (interface{}, error) {
+	tag, err := s.tagArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		tagInfo
+		Stat map[string]stats `json:"stat"`
+	}{s.tagInfo(tag), s.periodStats(func(m *SentMessage) bool { return hasTag(m, tag) })}, nil
+}
+
+func tagsTimeSeries(s *Server, body []byte) (interface{}, error) {
+	tag, err := s.tagArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return s.timeSeries(func(m *SentMessage) bool { return hasTag(m, tag) }), nil
+}
+
+func tagsAllTimeSeries(s *Server, body []byte) (interface{}, error) {
+	return s.timeSeries(func(m *SentMessage) bool {
+		for _, t := range m.Tags {
+			if !strings.HasPrefix(t, "_") && !s.deletedTags[t] {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
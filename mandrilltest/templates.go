@@ -0,0 +1,306 @@
+package mandrilltest
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/templates/add.json", templatesAdd)
+	route("/templates/info.json", templatesInfo)
+	route("/templates/update.json", templatesUpdate)
+	route("/templates/publish.json", templatesPublish)
+	route("/templates/delete.json", templatesDelete)
+	route("/templates/list.json", templatesList)
+	route("/templates/time-series.json", templatesTimeSeries)
+	route("/templates/render.json", templatesRender)
+}
+
+// templateFields are the editable fields of a template, in draft or published form
+type templateFields struct {
+	FromEmail string
+	FromName  string
+	Subject   string
+	Code      string
+	Text      string
+}
+
+type template struct {
+	slug        string
+	name        string
+	labels      []string
+	draft       templateFields
+	published   *templateFields
+	publishedAt time.Time
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+func (t *template) response() map[string]interface{} {
+	ret := map[string]interface{}{
+		"slug":       t.slug,
+		"name":       t.name,
+		"labels":     t.labels,
+		"code":       t.draft.Code,
+		"subject":    t.draft.Subject,
+		"from_email": t.draft.FromEmail,
+		"from_name":  t.draft.FromName,
+		"text":       t.draft.Text,
+		"created_at": formatTime(t.createdAt),
+		"updated_at": formatTime(t.updatedAt),
+	}
+	if t.published != nil {
+		ret["publish_name"] = t.name
+		ret["publish_code"] = t.published.Code
+		ret["publish_subject"] = t.published.Subject
+		ret["publish_from_email"] = t.published.FromEmail
+		ret["publish_from_name"] = t.published.FromName
+		ret["publish_text"] = t.published.Text
+		ret["published_at"] = formatTime(t.publishedAt)
+	}
+	return ret
+}
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// slugify returns the immutable code name for a template name
+func slugify(name string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// templateRequest holds the parameters of templates/add and templates/update
+type templateRequest struct {
+	Name      string    `json:"name"`
+	FromEmail *string   `json:"from_email"`
+	FromName  *string   `json:"from_name"`
+	Subject   *string   `json:"subject"`
+	Code      *string   `json:"code"`
+	Text      *string   `json:"text"`
+	Publish   bool      `json:"publish"`
+	Labels    *[]string `json:"labels"`
+}
+
+// apply sets the fields given in req on t
+func (req *templateRequest) apply(t *template) {
+	for _, f := range []struct {
+		src *string
+		dst *string
+	}{
+		{req.FromEmail, &t.draft.FromEmail},
+		{req.FromName, &t.draft.FromName},
+		{req.Subject, &t.draft.Subject},
+		{req.Code, &t.draft.Code},
+		{req.Text, &t.draft.Text},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	if req.Labels != nil {
+		t.labels = *req.Labels
+	}
+	t.updatedAt = time.Now().UTC()
+	if req.Publish {
+		t.publish()
+	}
+}
+
+func (t *template) publish() {
+	published := t.draft
+	t.published = &published
+	t.publishedAt = time.Now().UTC()
+}
+
+// template returns the template with the given name or slug
+func (s *Server) template(name string) (*template, error) {
+	if t := s.templates[slugify(name)]; t != nil {
+		return t, nil
+	}
+	return nil, &apiError{"error", 5, "Unknown_Template", "No such template \"" + name + "\""}
+}
+
+// templateArg decodes the name parameter and returns its template
+func (s *Server) templateArg(body []byte) (*template, error) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	return s.template(req.Name)
+}
+
+func templatesAdd(s *Server, body []byte) (interface{}, error) {
+	var req templateRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, errValidation("Validation error: {\"name\":\"Please enter a value\"}")
+	}
+	slug := slugify(req.Name)
+	if s.templates[slug] != nil {
+		return nil, &apiError{"error", 6, "Invalid_Template", "A template with name \"" + req.Name + "\" already exists"}
+	}
+	now := time.Now().UTC()
+	t := &template{slug: slug, name: req.Name, labels: []string{}, createdAt: now}
+	req.apply(t)
+	s.templates[slug] = t
+	return t.response(), nil
+}
+
+func templatesInfo(s *Server, body []byte) (interface{}, error) {
+	t, err := s.templateArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return t.response(), nil
+}
+
+func templatesUpdate(s *Server, body []byte) (interface{}, error) {
+	var req templateRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	t, err := s.template(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	req.apply(t)
+	return t.response(), nil
+}
+
+func templatesPublish(s *Server, body []byte) (interface{}, error) {
+	t, err := s.templateArg(body)
+	if err != nil {
+		return nil, err
+	}
+	t.publish()
+	return t.response(), nil
+}
+
+func templatesDelete(s *Server, body []byte) (interface{}, error) {
+	t, err := s.templateArg(body)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.templates, t.slug)
+	return t.response(), nil
+}
+
+func templatesList(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Label string `json:"label"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	ret := []map[string]interface{}{}
+	for _, t := range s.templates {
+		if req.Label != "" && !containsFold(t.labels, req.Label) {
+			continue
+		}
+		ret = append(ret, t.response())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["slug"].(string) < ret[j]["slug"].(string) })
+	return ret, nil
+}
+
+func templatesTimeSeries(s *Server, body []byte) (interface{}, error) {
+	t, err := s.templateArg(body)
+	if err != nil {
+		return nil, err
+	}
+	return s.timeSeries(func(m *SentMessage) bool { return m.Template == t.slug }), nil
+}
+
+// templateVar is a name and content pair used for template content and merge vars
+type templateVar struct {
+	Name    string      `json:"name"`
+	Content interface{} `json:"content"`
+}
+
+func templatesRender(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		TemplateName    string        `json:"template_name"`
+		TemplateContent []templateVar `json:"template_content"`
+		MergeVars       []templateVar `json:"merge_vars"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	t, err := s.template(req.TemplateName)
+	if err != nil {
+		return nil, err
+	}
+	code := t.draft.Code
+	if t.published != nil {
+		code = t.published.Code
+	}
+	return map[string]string{"html": render(code, req.TemplateContent, req.MergeVars)}, nil
+}
+
+var (
+	editableRegion = regexp.MustCompile(`(?s)<(\w+)([^>]*?)\s+mc:edit="([^"]*)"([^>]*)>(.*?)</(\w+)>`)
+	mergeTag       = regexp.MustCompile(`\*\|([A-Za-z0-9_]+)\|\*`)
+)
+
+// render replaces the mc:edit regions of code with content, then evaluates mailchimp
+// style merge tags if vars is not nil. Unknown merge tags are replaced with an empty string
+func render(code string, content []templateVar, vars []templateVar) string {
+	html := editableRegion.ReplaceAllStringFunc(code, func(region string) string {
+		m := editableRegion.FindStringSubmatch(region)
+		if m[1] != m[6] {
+			return region
+		}
+		inner := m[5]
+		for _, c := range content {
+			if c.Name == m[3] {
+				inner = toString(c.Content)
+			}
+		}
+		return "<" + m[1] + m[2] + m[4] + ">" + inner + "</" + m[1] + ">"
+	})
+	if vars == nil {
+		return html
+	}
+	return mergeTag.ReplaceAllStringFunc(html, mergeFunc(vars))
+}
+
+// mergeFunc returns a function replacing a merge tag with the first matching var
+func mergeFunc(vars []templateVar) func(string) string {
+	return func(tag string) string {
+		name := mergeTag.FindStringSubmatch(tag)[1]
+		for _, v := range vars {
+			if strings.EqualFold(v.Name, name) {
+				return toString(v.Content)
+			}
+		}
+		return ""
+	}
+}
+
+// toString formats a merge var's content
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
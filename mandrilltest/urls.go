@@ -0,0 +1,101 @@
+package mandrilltest
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/urls/list.json", urlsList)
+	route("/urls/search.json", urlsSearch)
+	route("/urls/time-series.json", urlsTimeSeries)
+	route("/urls/tracking-domains.json", urlsTrackingDomains)
+	route("/urls/add-tracking-domain.json", urlsAddTrackingDomain)
+	route("/urls/check-tracking-domain.json", urlsCheckTrackingDomain)
+}
+
+// trackingDomain is a custom domain for tracking opens and clicks. Domains
+// are reported as having a valid CNAME record
+type trackingDomain struct {
+	domain    string
+	createdAt time.Time
+}
+
+func (d *trackingDomain) response() map[string]interface{} {
+	now := formatTime(time.Now())
+	return map[string]interface{}{
+		"domain":         d.domain,
+		"created_at":     formatTime(d.createdAt),
+		"last_tested_at": now,
+		"cname":          map[string]interface{}{"valid": true, "valid_after": now, "error": ""},
+		"valid_tracking": true,
+	}
+}
+
+// clicks are not simulated, so no urls are ever tracked
+func urlsList(s *Server, body []byte) (interface{}, error) {
+	return []interface{}{}, nil
+}
+
+func urlsSearch(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Query string `json:"q"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	return []interface{}{}, nil
+}
+
+func urlsTimeSeries(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	return nil, &apiError{"error", 5, "Unknown_Url", "No url exists with the address '" + req.URL + "'"}
+}
+
+func urlsTrackingDomains(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, d := range s.trackingDomains {
+		ret = append(ret, d.response())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["domain"].(string) < ret[j]["domain"].(string) })
+	return ret, nil
+}
+
+func urlsAddTrackingDomain(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Domain string `json:"domain"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Domain == "" {
+		return nil, errValidation("Validation error: {\"domain\":\"Please enter a value\"}")
+	}
+	key := strings.ToLower(req.Domain)
+	d := s.trackingDomains[key]
+	if d == nil {
+		d = &trackingDomain{req.Domain, time.Now().UTC()}
+		s.trackingDomains[key] = d
+	}
+	return d.response(), nil
+}
+
+func urlsCheckTrackingDomain(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Domain string `json:"domain"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	d := s.trackingDomains[strings.ToLower(req.Domain)]
+	if d == nil {
+		return nil, &apiError{"error", 6, "Unknown_TrackingDomain", "No tracking domain exists with the name '" + req.Domain + "'"}
+	}
+	return d.response(), nil
+}
@@ -0,0 +1,71 @@
+package mandrilltest
+
+import (
+	"sort"
+	"time"
+)
+
+func init() {
+	route("/users/info.json", usersInfo)
+	route("/users/ping.json", usersPing)
+	route("/users/senders.json", usersSenders)
+}
+
+// periodStats returns stats for the standard reporting periods of messages matching match
+func (s *Server) periodStats(match func(*SentMessage) bool) map[string]stats {
+	now := time.Now().UTC()
+	day := 24 * time.Hour
+	return map[string]stats{
+		"today":        s.statsSince(now.Truncate(day), match),
+		"last_7_days":  s.statsSince(now.Add(-7*day), match),
+		"last_30_days": s.statsSince(now.Add(-30*day), match),
+		"last_60_days": s.statsSince(now.Add(-60*day), match),
+		"last_90_days": s.statsSince(now.Add(-90*day), match),
+		"all_time":     s.statsSince(time.Time{}, match),
+	}
+}
+
+func usersInfo(s *Server, body []byte) (interface{}, error) {
+	all := func(*SentMessage) bool { return true }
+	return map[string]interface{}{
+		"username":     "mandrilltest",
+		"created_at":   formatTime(time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		"public_id":    "mandrilltest",
+		"reputation":   75,
+		"hourly_quota": s.HourlyQuota,
+		"backlog":      s.Backlog,
+		"stats":        s.periodStats(all),
+	}, nil
+}
+
+func usersPing(s *Server, body []byte) (interface{}, error) {
+	return "PONG!", nil
+}
+
+// senderInfo is a sender address with its stats
+type senderInfo struct {
+	Address   string `json:"address"`
+	CreatedAt string `json:"created_at"`
+	stats
+}
+
+// senders returns every address that has sent a message, ordered by address
+func (s *Server) senders() []senderInfo {
+	index := make(map[string]int)
+	ret := []senderInfo{}
+	for _, m := range s.messages {
+		i, ok := index[m.Sender]
+		if !ok {
+			i = len(ret)
+			index[m.Sender] = i
+			ret = append(ret, senderInfo{Address: m.Sender, CreatedAt: formatTime(m.TS)})
+		}
+		ret[i].add(m)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Address < ret[j].Address })
+	return ret
+}
+
+func usersSenders(s *Server, body []byte) (interface{}, error) {
+	return s.senders(), nil
+}
@@ -0,0 +1,146 @@
+package mandrilltest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+func init() {
+	route("/webhooks/list.json", webhooksList)
+	route("/webhooks/add.json", webhooksAdd)
+	route("/webhooks/info.json", webhooksInfo)
+	route("/webhooks/update.json", webhooksUpdate)
+	route("/webhooks/delete.json", webhooksDelete)
+}
+
+// webhookEvents are the message events a webhook may subscribe to
+var webhookEvents = map[string]bool{
+	"send": true, "hard_bounce": true, "soft_bounce": true, "open": true, "click": true,
+	"spam": true, "unsub": true, "reject": true, "deferral": true,
+}
+
+type webhook struct {
+	id          int
+	url         string
+	description string
+	authKey     string
+	events      []string
+	createdAt   time.Time
+}
+
+func (w *webhook) response() map[string]interface{} {
+	return map[string]interface{}{
+		"id":           w.id,
+		"url":          w.url,
+		"description":  w.description,
+		"auth_key":     w.authKey,
+		"events":       w.events,
+		"created_at":   formatTime(w.createdAt),
+		"last_sent_at": "",
+		"batches_sent": 0,
+		"events_sent":  0,
+		"last_error":   "",
+	}
+}
+
+// webhookRequest holds the parameters of webhooks/add and webhooks/update
+type webhookRequest struct {
+	Id          int      `json:"id"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+}
+
+func (req *webhookRequest) validate() error {
+	if req.URL == "" {
+		return errValidation("Validation error: {\"url\":\"Please enter a value\"}")
+	}
+	for _, e := range req.Events {
+		if !webhookEvents[e] {
+			return errValidation("Validation error: {\"events\":\"Invalid event %s\"}", e)
+		}
+	}
+	return nil
+}
+
+// webhook returns the webhook with the given id
+func (s *Server) webhook(id int) (*webhook, error) {
+	if w := s.webhooks[id]; w != nil {
+		return w, nil
+	}
+	return nil, &apiError{"error", 7, "Unknown_Webhook", fmt.Sprintf("No webhook exists with the id '%d'", id)}
+}
+
+func webhooksList(s *Server, body []byte) (interface{}, error) {
+	ret := []map[string]interface{}{}
+	for _, w := range s.webhooks {
+		ret = append(ret, w.response())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["id"].(int) < ret[j]["id"].(int) })
+	return ret, nil
+}
+
+func webhooksAdd(s *Server, body []byte) (interface{}, error) {
+	var req webhookRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	s.nextId++
+	w := &webhook{
+		id:          s.nextId,
+		url:         req.URL,
+		description: req.Description,
+		authKey:     fmt.Sprintf("%022x", s.nextId),
+		events:      append([]string{}, req.Events...),
+		createdAt:   time.Now().UTC(),
+	}
+	s.webhooks[w.id] = w
+	return w.response(), nil
+}
+
+func webhooksInfo(s *Server, body []byte) (interface{}, error) {
+	var req webhookRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	w, err := s.webhook(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return w.response(), nil
+}
+
+func webhooksUpdate(s *Server, body []byte) (interface{}, error) {
+	var req webhookRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	w, err := s.webhook(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	w.url = req.URL
+	w.description = req.Description
+	w.events = append([]string{}, req.Events...)
+	return w.response(), nil
+}
+
+func webhooksDelete(s *Server, body []byte) (interface{}, error) {
+	var req webhookRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	w, err := s.webhook(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.webhooks, w.id)
+	return w.response(), nil
+}
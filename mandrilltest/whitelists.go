@@ -0,0 +1,70 @@
+package mandrilltest
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	route("/whitelists/add.json", whitelistsAdd)
+	route("/whitelists/delete.json", whitelistsDelete)
+	route("/whitelists/list.json", whitelistsList)
+}
+
+// whitelist is an address that is never rejected
+type whitelist struct {
+	email     string
+	detail    string
+	createdAt time.Time
+}
+
+func whitelistsAdd(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Email   string `json:"email"`
+		Comment string `json:"comment"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(req.Email, "@") {
+		return nil, errValidation("Validation error: {\"email\":\"An email address must contain a single @\"}")
+	}
+	s.whitelists[strings.ToLower(req.Email)] = &whitelist{req.Email, req.Comment, time.Now().UTC()}
+	return map[string]interface{}{"email": req.Email, "added": true}, nil
+}
+
+func whitelistsDelete(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	key := strings.ToLower(req.Email)
+	_, deleted := s.whitelists[key]
+	delete(s.whitelists, key)
+	return map[string]interface{}{"email": req.Email, "deleted": deleted}, nil
+}
+
+func whitelistsList(s *Server, body []byte) (interface{}, error) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+	ret := []map[string]interface{}{}
+	for _, w := range s.whitelists {
+		if req.Email != "" && !strings.EqualFold(req.Email, w.email) {
+			continue
+		}
+		ret = append(ret, map[string]interface{}{
+			"email":      w.email,
+			"detail":     w.detail,
+			"created_at": formatTime(w.createdAt),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i]["email"].(string) < ret[j]["email"].(string) })
+	return ret, nil
+}
@@ -0,0 +1,271 @@
+package mandrill
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MergeVars encodes v, a struct or a map with string keys, as merge vars. Struct fields are
+// named by their `mandrill:"name"` tag, or the field name if untagged; a tag of "-" skips
+// the field and the "omitempty" option skips zero values. Unexported fields are skipped and
+// the fields of embedded structs are promoted, as with encoding/json.
+//
+// Nested structs, maps and slices are encoded as objects and arrays for handlebars, using
+// the same naming rules. Top level names are checked against Mandrill's merge tag rules
+// and must be unique ignoring case; problems are returned as a *MessageError. Values that
+// contain themselves, such as a pointer back to an enclosing struct, return an error
+//
+//	type Order struct {
+//		Name  string `mandrill:"FNAME"`
+//		Items []Item `mandrill:"ITEMS"`
+//		Notes string `mandrill:"NOTES,omitempty"`
+//	}
+func MergeVars(v interface{}) ([]MergeVar, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("mandrill: cannot encode nil %s as merge vars", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+
+	var fields []structField
+	switch {
+	case rv.Kind() == reflect.Struct:
+		fields = structFields(rv)
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			fields = append(fields, structField{k.String(), rv.MapIndex(k)})
+		}
+	default:
+		return nil, fmt.Errorf("mandrill: cannot encode %T as merge vars, need a struct or map with string keys", v)
+	}
+
+	vars := make([]MergeVar, 0, len(fields))
+	enc := &mergeVarEncoder{path: make(map[visit]bool)}
+	for _, f := range fields {
+		content, err := enc.content(f.value)
+		if err != nil {
+			return nil, fmt.Errorf("mandrill: merge var %q: %w", f.name, err)
+		}
+		vars = append(vars, MergeVar{f.name, content})
+	}
+
+	var errs []error
+	seen := make(map[string]bool, len(vars))
+	for _, mv := range vars {
+		if err := checkMergeVarName(mv.Name); err != nil {
+			errs = append(errs, fmt.Errorf("merge vars: %s", err))
+		}
+		if seen[strings.ToLower(mv.Name)] {
+			errs = append(errs, fmt.Errorf("merge vars: %q is set more than once", mv.Name))
+		}
+		seen[strings.ToLower(mv.Name)] = true
+	}
+	if len(errs) > 0 {
+		return nil, &MessageError{errs}
+	}
+	return vars, nil
+}
+
+// RecipientMergeVars encodes v as the merge vars of the recipient email, see MergeVars
+func RecipientMergeVars(email string, v interface{}) (RecipientMergeVar, error) {
+	vars, err := MergeVars(v)
+	if err != nil {
+		return RecipientMergeVar{}, err
+	}
+	return RecipientMergeVar{email, vars}, nil
+}
+
+type structField struct {
+	name  string
+	value reflect.Value
+}
+
+// structFields returns the encoded fields of the struct rv, promoting the fields of
+// embedded structs
+func structFields(rv reflect.Value) []structField {
+	var ret []structField
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("mandrill")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		fv := rv.Field(i)
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Ptr {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				ret = append(ret, structFields(fv)...)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if hasOption(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+		ret = append(ret, structField{name, fv})
+	}
+	return ret
+}
+
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// visit identifies a pointer, map or slice being encoded
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// mergeVarEncoder converts values for MergeVar.Content, tracking the pointers, maps and
+// slices on the path to the current value to detect cycles
+type mergeVarEncoder struct {
+	path map[visit]bool
+}
+
+// content returns rv as a value for MergeVar.Content, converting structs, maps and
+// slices so that nested names follow the mandrill tags
+func (e *mergeVarEncoder) content(rv reflect.Value) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if isMarshaler(rv) {
+			return rv.Interface(), nil
+		}
+		if rv.Kind() == reflect.Ptr {
+			if err := e.enter(rv); err != nil {
+				return nil, err
+			}
+			defer e.leave(rv)
+		}
+		return e.content(rv.Elem())
+	}
+	if isMarshaler(rv) {
+		return rv.Interface(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		ret := make(map[string]interface{})
+		for _, f := range structFields(rv) {
+			c, err := e.content(f.value)
+			if err != nil {
+				return nil, err
+			}
+			ret[f.name] = c
+		}
+		return ret, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if err := e.enter(rv); err != nil {
+			return nil, err
+		}
+		defer e.leave(rv)
+		ret := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			c, err := e.content(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			ret[fmt.Sprint(iter.Key().Interface())] = c
+		}
+		return ret, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Interface(), nil
+		}
+		if rv.Kind() == reflect.Slice {
+			if err := e.enter(rv); err != nil {
+				return nil, err
+			}
+			defer e.leave(rv)
+		}
+		ret := make([]interface{}, rv.Len())
+		for i := range ret {
+			c, err := e.content(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = c
+		}
+		return ret, nil
+	}
+	return rv.Interface(), nil
+}
+
+// enter records the pointer, map or slice rv on the path, failing if it is already there
+func (e *mergeVarEncoder) enter(rv reflect.Value) error {
+	v := visit{rv.Pointer(), rv.Type(), 0}
+	if rv.Kind() == reflect.Slice {
+		v.len = rv.Len()
+	}
+	if e.path[v] {
+		return fmt.Errorf("encountered a cycle via %s", rv.Type())
+	}
+	e.path[v] = true
+	return nil
+}
+
+func (e *mergeVarEncoder) leave(rv reflect.Value) {
+	v := visit{rv.Pointer(), rv.Type(), 0}
+	if rv.Kind() == reflect.Slice {
+		v.len = rv.Len()
+	}
+	delete(e.path, v)
+}
+
+// isMarshaler reports whether rv encodes itself, such as time.Time
+func isMarshaler(rv reflect.Value) bool {
+	if !rv.CanInterface() {
+		return false
+	}
+	switch rv.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+	return false
+}
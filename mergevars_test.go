@@ -0,0 +1,138 @@
+package mandrill
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `mandrill:"city"`
+	Zip  string `mandrill:"-"`
+}
+
+type testContact struct {
+	Email string `mandrill:"EMAIL"`
+}
+
+type testItem struct {
+	Name  string  `mandrill:"name"`
+	Price float64 `mandrill:"price"`
+}
+
+type testOrder struct {
+	testContact
+	Name     string       `mandrill:"FNAME"`
+	Items    []testItem   `mandrill:"ITEMS"`
+	Address  *testAddress `mandrill:"ADDRESS"`
+	Notes    string       `mandrill:"NOTES,omitempty"`
+	Placed   time.Time    `mandrill:"PLACED"`
+	Total    int
+	internal string
+}
+
+func TestMergeVars(t *testing.T) {
+	placed := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	order := testOrder{
+		testContact: testContact{"jane@example.com"},
+		Name:        "Jane",
+		Items:       []testItem{{"Book", 9.5}},
+		Address:     &testAddress{"Sydney", "2000"},
+		Placed:      placed,
+		Total:       2,
+	}
+	vars, err := MergeVars(&order)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	b, _ := json.Marshal(vars)
+	expected := `[{"name":"EMAIL","content":"jane@example.com"},{"name":"FNAME","content":"Jane"},` +
+		`{"name":"ITEMS","content":[{"name":"Book","price":9.5}]},{"name":"ADDRESS","content":{"city":"Sydney"}},` +
+		`{"name":"PLACED","content":"2020-01-02T03:04:05Z"},{"name":"Total","content":2}]`
+	if string(b) != expected {
+		t.Errorf("unexpected merge vars. Received: %s", b)
+	}
+
+	vars, err = MergeVars(map[string]interface{}{"B": 2, "A": []int{1}})
+	if err != nil || len(vars) != 2 || vars[0].Name != "A" || vars[1].Name != "B" {
+		t.Errorf("expected map keys in order. Received: %+v, %v", vars, err)
+	}
+
+	rv, err := RecipientMergeVars("jane@example.com", map[string]string{"FNAME": "Jane"})
+	if err != nil || rv.Recipient != "jane@example.com" || len(rv.Vars) != 1 {
+		t.Errorf("unexpected recipient merge vars. Received: %+v, %v", rv, err)
+	}
+}
+
+func TestMergeVarsErrors(t *testing.T) {
+	var bad struct {
+		A string `mandrill:"_private"`
+		B string `mandrill:"a:b"`
+		C string `mandrill:"NAME"`
+		D string `mandrill:"name"`
+	}
+	_, err := MergeVars(bad)
+	var me *MessageError
+	if !errors.As(err, &me) || len(me.Errs) != 3 || !errors.Is(err, ErrValidation) {
+		t.Errorf("expected 3 validation errors. Received: %v", err)
+	}
+
+	if _, err := MergeVars([]string{"a"}); err == nil {
+		t.Error("expected error for slice")
+	}
+	var order *testOrder
+	if _, err := MergeVars(order); err == nil {
+		t.Error("expected error for nil pointer")
+	}
+}
+
+type testNode struct {
+	Name string      `mandrill:"NAME"`
+	Next *testNode   `mandrill:"NEXT,omitempty"`
+	Kids []*testNode `mandrill:"KIDS,omitempty"`
+}
+
+func TestMergeVarsCycle(t *testing.T) {
+	n := &testNode{Name: "a"}
+	n.Next = &testNode{Name: "b", Next: n}
+	if _, err := MergeVars(map[string]interface{}{"LIST": n}); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected cycle error. Received: %v", err)
+	}
+
+	loop := map[string]interface{}{}
+	loop["self"] = loop
+	if _, err := MergeVars(map[string]interface{}{"LOOP": loop}); err == nil {
+		t.Error("expected cycle error for map containing itself")
+	}
+
+	// a value referenced twice without a cycle is encoded each time
+	leaf := &testNode{Name: "leaf"}
+	vars, err := MergeVars(testNode{Name: "root", Kids: []*testNode{leaf, leaf}})
+	if err != nil || len(vars) != 2 || len(vars[1].Content.([]interface{})) != 2 {
+		t.Errorf("expected shared values to be encoded. Received: %+v, %v", vars, err)
+	}
+}
+
+func TestMessageBuilderVars(t *testing.T) {
+	msg, err := NewMessage().
+		From(TestFromEmail, "").
+		To("jane@example.com", "Jane").
+		Vars(map[string]string{"COMPANY": "Example"}).
+		RecipientVars("jane@example.com", testContact{"jane@example.com"}).
+		Build()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(msg.GlobalMergeVars) != 1 || len(msg.MergeVars) != 1 || msg.MergeVars[0].Vars[0].Name != "EMAIL" || !msg.Merge {
+		t.Errorf("unexpected merge vars. Received: %+v, %+v", msg.GlobalMergeVars, msg.MergeVars)
+	}
+
+	_, err = NewMessage().From(TestFromEmail, "").To("jane@example.com", "").Vars(map[string]int{"_X": 1}).Build()
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("expected validation error. Received: %v", err)
+	}
+}
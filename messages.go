@@ -1,7 +1,14 @@
 package mandrill
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/mail"
+	"strings"
 	"time"
 )
 
@@ -10,6 +17,11 @@ type Messages struct {
 }
 
 func (m *Messages) Send(message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	return m.SendContext(context.Background(), message, async, ipPool, sendAt)
+}
+
+// SendContext is like Send but carries ctx through the request
+func (m *Messages) SendContext(ctx context.Context, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
 	var tsend string
 	if sendAt != nil {
 		tsend = ToMandrillTime(*sendAt)
@@ -31,13 +43,64 @@ func (m *Messages) Send(message *Message, async bool, ipPool string, sendAt *tim
 		IPPool string `json:"ip_pool,omitempty"`
 		SendAt string `json:"send_at,omitempty"`
 	}{m.m.APIKey, message, async, ipPool, tsend}
-	resp, err := m.m.execute("/messages/send.json", data)
+	if err := m.validate(message); err != nil {
+		return nil, err
+	}
+	if err := m.wait(ctx, message, sendAt); err != nil {
+		return nil, err
+	}
+	resp, err := m.m.execute(ctx, "/messages/send.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []SendResponse
+	err = decode("/messages/send.json", resp, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// SendTemplate sends a new transactional message using a template.
+// templateName is the immutable name or slug of a template in the account.
+// templateContent fills the editable mc:edit regions of the template
+func (m *Messages) SendTemplate(templateName string, templateContent []TemplateMergeVar, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	return m.SendTemplateContext(context.Background(), templateName, templateContent, message, async, ipPool, sendAt)
+}
+
+// SendTemplateContext is like SendTemplate but carries ctx through the request
+func (m *Messages) SendTemplateContext(ctx context.Context, templateName string, templateContent []TemplateMergeVar, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	var tsend string
+	if sendAt != nil {
+		tsend = ToMandrillTime(*sendAt)
+	}
+	if templateContent == nil {
+		templateContent = []TemplateMergeVar{}
+	}
+	data := struct {
+		APIkey          string             `json:"key"`
+		TemplateName    string             `json:"template_name"`
+		TemplateContent []TemplateMergeVar `json:"template_content"`
+		Message         *Message           `json:"message"`
+		Async           bool               `json:"async,omitempty"`
+		IPPool          string             `json:"ip_pool,omitempty"`
+		SendAt          string             `json:"send_at,omitempty"`
+	}{m.m.APIKey, templateName, templateContent, message, async, ipPool, tsend}
+	if err := m.validate(message); err != nil {
+		return nil, err
+	}
+	if err := m.wait(ctx, message, sendAt); err != nil {
+		return nil, err
+	}
+	resp, err := m.m.execute(ctx, "/messages/send-template.json", data)
 	if err != nil {
 		return nil, err
 	}
 
 	var ret []SendResponse
-	err = json.Unmarshal(resp, &ret)
+	err = decode("/messages/send-template.json", resp, &ret)
 	if err != nil {
 		return nil, err
 	}
@@ -45,6 +108,216 @@ func (m *Messages) Send(message *Message, async bool, ipPool string, sendAt *tim
 	return ret, nil
 }
 
+// MessagesSendRawRequest holds the optional parameters for sending a raw MIME message
+type MessagesSendRawRequest struct {
+	// the sender email address. If not set, the address from the message headers is used
+	FromEmail string
+
+	// the sender name. If not set, the name from the message headers is used
+	FromName string
+
+	// the recipient addresses. If not set, the To, Cc and Bcc headers are used
+	To []string
+
+	// enable a background sending mode that is optimized for bulk sending
+	Async bool
+
+	// the name of the dedicated ip pool that should be used to send the message
+	IPPool string
+
+	// when the message should be sent. If nil the message is sent immediately
+	SendAt *time.Time
+
+	// a custom domain to use for the message's return-path
+	ReturnPathDomain string
+}
+
+// SendRaw sends a new transactional message from a full RFC 5322 MIME document.
+// A nil r sends to the recipients in the message headers
+func (m *Messages) SendRaw(rawMessage string, r *MessagesSendRawRequest) ([]SendResponse, error) {
+	return m.SendRawContext(context.Background(), rawMessage, r)
+}
+
+// SendRawContext is like SendRaw but carries ctx through the request
+func (m *Messages) SendRawContext(ctx context.Context, rawMessage string, r *MessagesSendRawRequest) ([]SendResponse, error) {
+	if r == nil {
+		r = &MessagesSendRawRequest{}
+	}
+	var tsend string
+	if r.SendAt != nil {
+		tsend = ToMandrillTime(*r.SendAt)
+	}
+	data := struct {
+		APIKey           string   `json:"key"`
+		RawMessage       string   `json:"raw_message"`
+		FromEmail        string   `json:"from_email,omitempty"`
+		FromName         string   `json:"from_name,omitempty"`
+		To               []string `json:"to,omitempty"`
+		Async            bool     `json:"async,omitempty"`
+		IPPool           string   `json:"ip_pool,omitempty"`
+		SendAt           string   `json:"send_at,omitempty"`
+		ReturnPathDomain string   `json:"return_path_domain,omitempty"`
+	}{m.m.APIKey, rawMessage, r.FromEmail, r.FromName, r.To, r.Async, r.IPPool, tsend, r.ReturnPathDomain}
+	subaccount, recipients := rawRecipients(rawMessage)
+	if len(r.To) > 0 {
+		recipients = len(r.To)
+	}
+	if err := m.waitRecipients(ctx, subaccount, recipients, r.SendAt); err != nil {
+		return nil, err
+	}
+	resp, err := m.m.execute(ctx, "/messages/send-raw.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []SendResponse
+	err = decode("/messages/send-raw.json", resp, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// SendRawReader is like SendRaw but reads the raw message from rawMessage
+func (m *Messages) SendRawReader(rawMessage io.Reader, r *MessagesSendRawRequest) ([]SendResponse, error) {
+	return m.SendRawReaderContext(context.Background(), rawMessage, r)
+}
+
+// SendRawReaderContext is like SendRawReader but carries ctx through the request
+func (m *Messages) SendRawReaderContext(ctx context.Context, rawMessage io.Reader, r *MessagesSendRawRequest) ([]SendResponse, error) {
+	b, err := ioutil.ReadAll(rawMessage)
+	if err != nil {
+		return nil, err
+	}
+	return m.SendRawContext(ctx, string(b), r)
+}
+
+// Parse parses a full RFC 5322 MIME document into a Message that can be modified and sent.
+// Only the Reply-To and X-* headers are kept, as those are the headers Send accepts
+func (m *Messages) Parse(rawMessage string) (*Message, error) {
+	return m.ParseContext(context.Background(), rawMessage)
+}
+
+// ParseContext is like Parse but carries ctx through the request
+func (m *Messages) ParseContext(ctx context.Context, rawMessage string) (*Message, error) {
+	data := struct {
+		APIKey     string `json:"key"`
+		RawMessage string `json:"raw_message"`
+	}{m.m.APIKey, rawMessage}
+	body, err := m.m.execute(ctx, "/messages/parse.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret struct {
+		Subject     string                 `json:"subject"`
+		FromEmail   string                 `json:"from_email"`
+		FromName    string                 `json:"from_name"`
+		To          []Recipient            `json:"to"`
+		Headers     map[string]interface{} `json:"headers"`
+		Text        string                 `json:"text"`
+		HTML        string                 `json:"html"`
+		Attachments []struct {
+			Name    string `json:"name"`
+			Type    string `json:"type"`
+			Binary  bool   `json:"binary"`
+			Content string `json:"content"`
+		} `json:"attachments"`
+		Images []*Image `json:"images"`
+	}
+	err = decode("/messages/parse.json", body, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Subject:   ret.Subject,
+		FromEmail: ret.FromEmail,
+		FromName:  ret.FromName,
+		To:        ret.To,
+		Text:      ret.Text,
+		HTML:      ret.HTML,
+		Images:    ret.Images,
+	}
+	for k, v := range ret.Headers {
+		if !strings.EqualFold(k, "Reply-To") && !strings.HasPrefix(strings.ToLower(k), "x-") {
+			continue
+		}
+		if msg.Headers == nil {
+			msg.Headers = make(map[string]string)
+		}
+		msg.Headers[k] = headerValue(v)
+	}
+	for _, a := range ret.Attachments {
+		content := a.Content
+		if !a.Binary {
+			content = base64.StdEncoding.EncodeToString([]byte(a.Content))
+		}
+		msg.Attachments = append(msg.Attachments, &Attachment{Name: a.Name, Type: a.Type, Content: content})
+	}
+
+	return msg, nil
+}
+
+// headerValue returns a parsed header value, which is a list if the header was repeated, as a string
+func headerValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, s := range v {
+			values[i] = fmt.Sprint(s)
+		}
+		return strings.Join(values, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// validate runs message.Validate if enabled by WithValidation
+func (m *Messages) validate(message *Message) error {
+	if !m.m.ValidateMessages || message == nil {
+		return nil
+	}
+	return message.Validate()
+}
+
+// wait blocks until the quota limiter, if any, allows message to be sent.
+// Scheduled messages count against the quota when delivered, not now
+func (m *Messages) wait(ctx context.Context, message *Message, sendAt *time.Time) error {
+	if message == nil {
+		return nil
+	}
+	return m.waitRecipients(ctx, message.SubAccount, len(message.To), sendAt)
+}
+
+// waitRecipients is like wait for n recipients sent from subaccount, counting at least one
+func (m *Messages) waitRecipients(ctx context.Context, subaccount string, n int, sendAt *time.Time) error {
+	if m.m.Limiter == nil || sendAt != nil {
+		return nil
+	}
+	if n < 1 {
+		n = 1
+	}
+	return m.m.Limiter.wait(ctx, m.m, subaccount, n)
+}
+
+// rawRecipients returns the X-MC-Subaccount header of a raw message and the number of
+// addresses in its To, Cc and Bcc headers
+func rawRecipients(rawMessage string) (string, int) {
+	msg, err := mail.ReadMessage(strings.NewReader(rawMessage))
+	if err != nil {
+		return "", 0
+	}
+	n := 0
+	for _, h := range []string{"To", "Cc", "Bcc"} {
+		list, _ := msg.Header.AddressList(h)
+		n += len(list)
+	}
+	return strings.TrimSpace(msg.Header.Get("X-MC-Subaccount")), n
+}
+
 type SendResponse struct {
 	// the email address of the recipient
 	Email string `json:"email"`
@@ -171,6 +444,19 @@ type Message struct {
 	Images []*Image `json:"images,omitempty"`
 }
 
+// Recipient types
+const (
+	RecipientTo  = "to"
+	RecipientCC  = "cc"
+	RecipientBCC = "bcc"
+)
+
+// Merge languages
+const (
+	MergeLanguageMailchimp  = "mailchimp"
+	MergeLanguageHandlebars = "handlebars"
+)
+
 type Recipient struct {
 	Email string `json:"email"`
 	Name  string `json:"name,omitempty"`
@@ -194,8 +480,9 @@ type RecipientMergeVar struct {
 }
 
 type RecipientMetadata struct {
-	Recipient string
-	Values    map[string]string
+	// the email address of the recipient that the metadata is associated with
+	Recipient string            `json:"rcpt"`
+	Values    map[string]string `json:"values"`
 }
 
 type Attachment struct {
@@ -219,3 +506,505 @@ type Image struct {
 	// the content of the image as a base64-encoded string
 	Content string `json:"content,omitempty"`
 }
+
+// MessagesSearchRequest narrows a search of recently sent messages
+type MessagesSearchRequest struct {
+	// search terms to find matching messages, e.g. "email:gmail.com" or "u_customer_id:123".
+	// Defaults to "*" which matches every message
+	Query string
+
+	// optional start and end of the date range to search, inclusive. Only the date is used
+	DateFrom time.Time
+	DateTo   time.Time
+
+	// an array of tag names to narrow the search to; will return messages that contain ANY of the tags
+	Tags []string
+
+	// an array of sender addresses to narrow the search to
+	Senders []string
+
+	// an array of api keys to narrow the search to; will return messages sent by ANY of the keys
+	APIKeys []string
+
+	// the maximum number of results to return, defaults to 100, 1000 is the maximum
+	Limit int
+}
+
+// Search returns messages matching r, most recent first. A nil r matches every message.
+// Search results are only available for 30 days after sending
+func (m *Messages) Search(r *MessagesSearchRequest) ([]MessageInfo, error) {
+	return m.SearchContext(context.Background(), r)
+}
+
+// SearchContext is like Search but carries ctx through the request
+func (m *Messages) SearchContext(ctx context.Context, r *MessagesSearchRequest) ([]MessageInfo, error) {
+	if r == nil {
+		r = &MessagesSearchRequest{}
+	}
+	data := struct {
+		APIKey   string   `json:"key"`
+		Query    string   `json:"query,omitempty"`
+		DateFrom string   `json:"date_from,omitempty"`
+		DateTo   string   `json:"date_to,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
+		Senders  []string `json:"senders,omitempty"`
+		APIKeys  []string `json:"api_keys,omitempty"`
+		Limit    int      `json:"limit,omitempty"`
+	}{m.m.APIKey, r.Query, searchDate(r.DateFrom), searchDate(r.DateTo), r.Tags, r.Senders, r.APIKeys, r.Limit}
+	body, err := m.m.execute(ctx, "/messages/search.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []MessageInfo
+	err = decode("/messages/search.json", body, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// SearchTimeSeries returns hourly stats for messages matching r, oldest first.
+// Limit and APIKeys are not used. A nil r matches every message
+func (m *Messages) SearchTimeSeries(r *MessagesSearchRequest) ([]MessagesTimeSeries, error) {
+	return m.SearchTimeSeriesContext(context.Background(), r)
+}
+
+// SearchTimeSeriesContext is like SearchTimeSeries but carries ctx through the request
+func (m *Messages) SearchTimeSeriesContext(ctx context.Context, r *MessagesSearchRequest) ([]MessagesTimeSeries, error) {
+	if r == nil {
+		r = &MessagesSearchRequest{}
+	}
+	data := struct {
+		APIKey   string   `json:"key"`
+		Query    string   `json:"query,omitempty"`
+		DateFrom string   `json:"date_from,omitempty"`
+		DateTo   string   `json:"date_to,omitempty"`
+		Tags     []string `json:"tags,omitempty"`
+		Senders  []string `json:"senders,omitempty"`
+	}{m.m.APIKey, r.Query, searchDate(r.DateFrom), searchDate(r.DateTo), r.Tags, r.Senders}
+	body, err := m.m.execute(ctx, "/messages/search-time-series.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []MessagesTimeSeries
+	err = decode("/messages/search-time-series.json", body, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// MessagesTimeSeries is an hour of stats for messages matching a search. The counters are
+// those of TagTimeSeries, whose Time holds the hour as returned by the api
+type MessagesTimeSeries struct {
+	TagTimeSeries
+
+	// the hour as a UTC time
+	Hour time.Time `json:"-"`
+}
+
+func (ts *MessagesTimeSeries) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &ts.TagTimeSeries); err != nil {
+		return err
+	}
+	var err error
+	ts.Hour, err = optionalMandrillTime(ts.Time)
+	return err
+}
+
+// Info returns the information for a single recently sent message
+func (m *Messages) Info(id string) (MessageInfo, error) {
+	return m.InfoContext(context.Background(), id)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (m *Messages) InfoContext(ctx context.Context, id string) (MessageInfo, error) {
+	var ret MessageInfo
+	data := struct {
+		APIKey string `json:"key"`
+		Id     string `json:"id"`
+	}{m.m.APIKey, id}
+	body, err := m.m.execute(ctx, "/messages/info.json", data)
+	if err != nil {
+		return ret, err
+	}
+
+	err = decode("/messages/info.json", body, &ret)
+	if err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+// Content returns the full content of a recently sent message
+func (m *Messages) Content(id string) (MessageContent, error) {
+	return m.ContentContext(context.Background(), id)
+}
+
+// ContentContext is like Content but carries ctx through the request
+func (m *Messages) ContentContext(ctx context.Context, id string) (MessageContent, error) {
+	var ret MessageContent
+	data := struct {
+		APIKey string `json:"key"`
+		Id     string `json:"id"`
+	}{m.m.APIKey, id}
+	body, err := m.m.execute(ctx, "/messages/content.json", data)
+	if err != nil {
+		return ret, err
+	}
+
+	err = decode("/messages/content.json", body, &ret)
+	if err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+// MessageContent is the content of a sent message as retained by Mandrill
+type MessageContent struct {
+	// the time the message was sent
+	TS time.Time `json:"-"`
+
+	// the message's unique id
+	Id string `json:"_id"`
+
+	// the email address and name of the sender
+	FromEmail string `json:"from_email"`
+	FromName  string `json:"from_name"`
+
+	Subject string `json:"subject"`
+
+	// the recipient of the message
+	To Recipient `json:"to"`
+
+	Tags []string `json:"tags"`
+
+	// the key-value pairs of the custom MIME headers for the message's main document.
+	// Repeated headers, such as Received, are joined with ", "
+	Headers map[string]string `json:"-"`
+
+	// the text part and html part of the message, if any
+	Text string `json:"text"`
+	HTML string `json:"html"`
+
+	// the attachments of the message, if any
+	Attachments []ContentAttachment `json:"attachments"`
+}
+
+func (c *MessageContent) UnmarshalJSON(b []byte) error {
+	type fakeContent MessageContent
+	aux := struct {
+		TS      int64                  `json:"ts"`
+		Headers map[string]interface{} `json:"headers"`
+		*fakeContent
+	}{fakeContent: (*fakeContent)(c)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	c.TS = fromUnix(aux.TS)
+	c.Headers = nil
+	if aux.Headers != nil {
+		c.Headers = make(map[string]string, len(aux.Headers))
+		for k, v := range aux.Headers {
+			c.Headers[k] = headerValue(v)
+		}
+	}
+	return nil
+}
+
+// ContentAttachment is an attachment of a sent message
+type ContentAttachment struct {
+	// the file name of the attachment
+	Name string `json:"name"`
+
+	// the MIME type of the attachment
+	Type string `json:"type"`
+
+	// the content of the attachment, decoded from base64
+	Content []byte `json:"content"`
+}
+
+// ListScheduled returns the scheduled messages, optionally filtered by recipient address
+func (m *Messages) ListScheduled(to string) ([]ScheduledMessage, error) {
+	return m.ListScheduledContext(context.Background(), to)
+}
+
+// ListScheduledContext is like ListScheduled but carries ctx through the request
+func (m *Messages) ListScheduledContext(ctx context.Context, to string) ([]ScheduledMessage, error) {
+	data := struct {
+		APIKey string `json:"key"`
+		To     string `json:"to,omitempty"`
+	}{m.m.APIKey, to}
+	body, err := m.m.execute(ctx, "/messages/list-scheduled.json", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []ScheduledMessage
+	err = decode("/messages/list-scheduled.json", body, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CancelScheduled cancels a scheduled message, returning the message as it was scheduled
+func (m *Messages) CancelScheduled(id string) (ScheduledMessage, error) {
+	return m.CancelScheduledContext(context.Background(), id)
+}
+
+// CancelScheduledContext is like CancelScheduled but carries ctx through the request
+func (m *Messages) CancelScheduledContext(ctx context.Context, id string) (ScheduledMessage, error) {
+	var ret ScheduledMessage
+	data := struct {
+		APIKey string `json:"key"`
+		Id     string `json:"id"`
+	}{m.m.APIKey, id}
+	body, err := m.m.execute(ctx, "/messages/cancel-scheduled.json", data)
+	if err != nil {
+		return ret, err
+	}
+
+	err = decode("/messages/cancel-scheduled.json", body, &ret)
+	if err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+// Reschedule changes the time a scheduled message will be sent
+func (m *Messages) Reschedule(id string, sendAt time.Time) (ScheduledMessage, error) {
+	return m.RescheduleContext(context.Background(), id, sendAt)
+}
+
+// RescheduleContext is like Reschedule but carries ctx through the request
+func (m *Messages) RescheduleContext(ctx context.Context, id string, sendAt time.Time) (ScheduledMessage, error) {
+	var ret ScheduledMessage
+	data := struct {
+		APIKey string `json:"key"`
+		Id     string `json:"id"`
+		SendAt string `json:"send_at"`
+	}{m.m.APIKey, id, ToMandrillTime(sendAt)}
+	body, err := m.m.execute(ctx, "/messages/reschedule.json", data)
+	if err != nil {
+		return ret, err
+	}
+
+	err = decode("/messages/reschedule.json", body, &ret)
+	if err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
+
+// ScheduledMessage is a message scheduled to be sent to one recipient
+type ScheduledMessage struct {
+	// the scheduled message id
+	Id string `json:"_id"`
+
+	// when the message was scheduled and when it will be sent, in UTC
+	CreatedAt time.Time `json:"-"`
+	SendAt    time.Time `json:"-"`
+
+	// the email's sender address
+	FromEmail string `json:"from_email"`
+
+	// the email's recipient
+	To string `json:"to"`
+
+	// the email's subject
+	Subject string `json:"subject"`
+}
+
+func (s *ScheduledMessage) UnmarshalJSON(b []byte) error {
+	type fakeScheduled ScheduledMessage
+	aux := struct {
+		CreatedAt string `json:"created_at"`
+		SendAt    string `json:"send_at"`
+		*fakeScheduled
+	}{fakeScheduled: (*fakeScheduled)(s)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	var err error
+	if s.CreatedAt, err = optionalMandrillTime(aux.CreatedAt); err != nil {
+		return err
+	}
+	if s.SendAt, err = optionalMandrillTime(aux.SendAt); err != nil {
+		return err
+	}
+	return nil
+}
+
+// optionalMandrillTime is like FromMandrillTime but returns the zero time for an empty string
+func optionalMandrillTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return FromMandrillTime(s)
+}
+
+// searchDate returns the UTC date of t as used by the search api, or an empty string if t is zero
+func searchDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// MessageInfo is the information recorded for a message sent to one recipient
+type MessageInfo struct {
+	// the time the message was sent
+	TS time.Time `json:"-"`
+
+	// the message's unique id
+	Id string `json:"_id"`
+
+	// the email address of the sender
+	Sender string `json:"sender"`
+
+	// the unique name of the template used, if any
+	Template string `json:"template"`
+
+	Subject string `json:"subject"`
+
+	// the recipient email address
+	Email string `json:"email"`
+
+	Tags []string `json:"tags"`
+
+	// how many times the message has been opened, and when and where
+	Opens       int          `json:"opens"`
+	OpensDetail []OpenDetail `json:"opens_detail"`
+
+	// how many times links in the message have been clicked, and when and where
+	Clicks       int           `json:"clicks"`
+	ClicksDetail []ClickDetail `json:"clicks_detail"`
+
+	// sending status of the message - either "sent", "bounced", "rejected", "soft-bounced",
+	// "spam", "unsub", "deferred", "queued" or "scheduled"
+	State string `json:"state"`
+
+	// any custom metadata provided when the message was sent
+	Metadata map[string]string `json:"metadata"`
+
+	// the unique id of the subaccount the message was sent from, if any
+	Subaccount string `json:"subaccount"`
+
+	// the smtp events that occurred when delivering the message
+	SMTPEvents []SMTPEvent `json:"smtp_events"`
+}
+
+func (i *MessageInfo) UnmarshalJSON(b []byte) error {
+	type fakeInfo MessageInfo
+	aux := struct {
+		TS int64 `json:"ts"`
+		*fakeInfo
+	}{fakeInfo: (*fakeInfo)(i)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	i.TS = fromUnix(aux.TS)
+	return nil
+}
+
+// OpenDetail records a single open of a message
+type OpenDetail struct {
+	// the time the message was opened
+	TS time.Time `json:"-"`
+
+	// the IP address that generated the open
+	IP string `json:"ip"`
+
+	// the approximate region and country that the opening IP is located
+	Location string `json:"location"`
+
+	// the email client or browser data of the open
+	UA string `json:"ua"`
+}
+
+func (o *OpenDetail) UnmarshalJSON(b []byte) error {
+	type fakeDetail OpenDetail
+	aux := struct {
+		TS int64 `json:"ts"`
+		*fakeDetail
+	}{fakeDetail: (*fakeDetail)(o)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	o.TS = fromUnix(aux.TS)
+	return nil
+}
+
+// ClickDetail records a single click of a link in a message
+type ClickDetail struct {
+	// the time the link was clicked
+	TS time.Time `json:"-"`
+
+	// the URL that was clicked on
+	URL string `json:"url"`
+
+	// the IP address that generated the click
+	IP string `json:"ip"`
+
+	// the approximate region and country that the clicking IP is located
+	Location string `json:"location"`
+
+	// the email client or browser data of the click
+	UA string `json:"ua"`
+}
+
+func (c *ClickDetail) UnmarshalJSON(b []byte) error {
+	type fakeDetail ClickDetail
+	aux := struct {
+		TS int64 `json:"ts"`
+		*fakeDetail
+	}{fakeDetail: (*fakeDetail)(c)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	c.TS = fromUnix(aux.TS)
+	return nil
+}
+
+// SMTPEvent records a single smtp response from the recipient's server
+type SMTPEvent struct {
+	// the time the event occurred
+	TS time.Time `json:"-"`
+
+	// the type of event, e.g. "sent", "deferred" or "bounced"
+	Type string `json:"type"`
+
+	// the SMTP response from the recipient's server
+	Diag string `json:"diag"`
+}
+
+func (e *SMTPEvent) UnmarshalJSON(b []byte) error {
+	type fakeEvent SMTPEvent
+	aux := struct {
+		TS int64 `json:"ts"`
+		*fakeEvent
+	}{fakeEvent: (*fakeEvent)(e)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	e.TS = fromUnix(aux.TS)
+	return nil
+}
+
+// fromUnix returns the UTC time of a unix timestamp, or the zero time if ts is zero
+func fromUnix(ts int64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0).UTC()
+}
@@ -1,7 +1,11 @@
 package mandrill
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSendMessage(t *testing.T) {
@@ -49,3 +53,391 @@ func TestSendMessageReject(t *testing.T) {
 		t.Errorf("Expected rejected response. Response: %+v", rr[0])
 	}
 }
+
+func TestSendTemplate(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	name := "53103-test-send-template"
+	m.Templates().Delete(name)
+	tpl := &Template{
+		Name:      name,
+		FromEmail: TestFromEmail,
+		Subject:   "Test Subject",
+		Code:      `<div mc:edit="greeting"></div>`,
+		Publish:   true,
+	}
+	if _, err := m.Templates().Add(tpl); err != nil {
+		t.Error(err)
+		return
+	}
+	defer m.Templates().Delete(name)
+
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com"}},
+	}
+	content := []TemplateMergeVar{{"greeting", "Hello"}}
+	rr, err := m.Messages().SendTemplate(name, content, msg, false, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(rr) != 1 || rr[0].Email != "accept@test.mandrillapp.com" || rr[0].Status == "rejected" {
+		t.Errorf("Expected 1 accepted response. Received: %+v", rr)
+	}
+}
+
+func TestSendTemplateUnknown(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com"}},
+	}
+	_, err := m.Messages().SendTemplate("53104-bad-test-template", nil, msg, false, "", nil)
+	if ae, ok := err.(*APIError); !ok || ae.Name != "Unknown_Template" {
+		t.Errorf("expected unknown template error. Received: %s", err)
+	}
+}
+
+func TestMessagesSearch(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		Subject:   "Test Search",
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com"}},
+		Tags:      []string{"53105-test-search"},
+		Metadata:  map[string]string{"search_id": "53105"},
+	}
+	rr, err := m.Messages().Send(msg, false, "", nil)
+	if err != nil || len(rr) != 1 {
+		t.Errorf("expected 1 response. Received: %+v, %v", rr, err)
+		return
+	}
+
+	ret, err := m.Messages().Search(&MessagesSearchRequest{
+		Query:    "u_search_id:53105",
+		DateFrom: time.Now().AddDate(0, 0, -1),
+		DateTo:   time.Now(),
+		Tags:     []string{"53105-test-search"},
+		Senders:  []string{TestFromEmail},
+		Limit:    10,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ret) == 0 {
+		t.Errorf("expected search to find sent message. Received: %+v", ret)
+		return
+	}
+	r := ret[0]
+	if r.Id != rr[0].Id || r.Email != "accept@test.mandrillapp.com" || r.Subject != "Test Search" || r.Metadata["search_id"] != "53105" {
+		t.Errorf("unexpected search result. Received: %+v", r)
+	}
+	if r.TS.IsZero() || time.Since(r.TS) > time.Hour {
+		t.Errorf("expected recent timestamp. Received: %v", r.TS)
+	}
+}
+
+func TestMessagesSearchNoMatch(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	ret, err := m.Messages().Search(&MessagesSearchRequest{Query: "u_search_id:53106-none"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ret) != 0 {
+		t.Errorf("expected no results. Received: %+v", ret)
+	}
+}
+
+func TestMessageInfoUnmarshal(t *testing.T) {
+	b := []byte(`{"ts":1365190000,"_id":"abc","state":"sent","opens":1,"opens_detail":[{"ts":1365190001,"ip":"1.2.3.4","location":"Georgia, US","ua":"Linux/Ubuntu/Chrome"}],"clicks":1,"clicks_detail":[{"ts":1365190002,"url":"http://example.com"}],"smtp_events":[{"ts":1365190003,"type":"sent","diag":"250 OK"}]}`)
+	var info MessageInfo
+	if err := json.Unmarshal(b, &info); err != nil {
+		t.Error(err)
+		return
+	}
+	if info.TS.Unix() != 1365190000 || info.Id != "abc" || info.State != "sent" {
+		t.Errorf("unexpected info. Received: %+v", info)
+	}
+	if len(info.OpensDetail) != 1 || info.OpensDetail[0].TS.Unix() != 1365190001 || info.OpensDetail[0].IP != "1.2.3.4" {
+		t.Errorf("unexpected opens detail. Received: %+v", info.OpensDetail)
+	}
+	if len(info.ClicksDetail) != 1 || info.ClicksDetail[0].TS.Unix() != 1365190002 || info.ClicksDetail[0].URL != "http://example.com" {
+		t.Errorf("unexpected clicks detail. Received: %+v", info.ClicksDetail)
+	}
+	if len(info.SMTPEvents) != 1 || info.SMTPEvents[0].TS.Unix() != 1365190003 || info.SMTPEvents[0].Diag != "250 OK" {
+		t.Errorf("unexpected smtp events. Received: %+v", info.SMTPEvents)
+	}
+}
+
+func TestMessagesSearchTimeSeries(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com"}},
+		Tags:      []string{"53107-test-search-time-series"},
+	}
+	if _, err := m.Messages().Send(msg, false, "", nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	ret, err := m.Messages().SearchTimeSeries(&MessagesSearchRequest{
+		Tags:     []string{"53107-test-search-time-series"},
+		DateFrom: time.Now().AddDate(0, 0, -1),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ret) == 0 {
+		t.Errorf("expected at least 1 bucket. Received: %+v", ret)
+		return
+	}
+	last := ret[len(ret)-1]
+	if last.Hour.IsZero() || last.Hour.Minute() != 0 || last.Time == "" || last.Sent == 0 {
+		t.Errorf("expected hourly bucket with sent messages. Received: %+v", last)
+	}
+}
+
+func TestMessagesInfoContent(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		FromName:  "Test Sender",
+		Subject:   "Test Info",
+		HTML:      "<p>Test Info</p>",
+		Text:      "Test Info",
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com", Name: "Test Recipient"}},
+		Attachments: []*Attachment{
+			{Name: "test.txt", Type: "text/plain", Content: "dGVzdCBhdHRhY2htZW50"},
+		},
+	}
+	rr, err := m.Messages().Send(msg, false, "", nil)
+	if err != nil || len(rr) != 1 {
+		t.Errorf("expected 1 response. Received: %+v, %v", rr, err)
+		return
+	}
+
+	info, err := m.Messages().Info(rr[0].Id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if info.Id != rr[0].Id || info.Email != "accept@test.mandrillapp.com" || info.Subject != "Test Info" || info.TS.IsZero() {
+		t.Errorf("unexpected info. Received: %+v", info)
+	}
+
+	c, err := m.Messages().Content(rr[0].Id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if c.Id != rr[0].Id || c.FromEmail != TestFromEmail || c.Subject != "Test Info" || c.To.Email != "accept@test.mandrillapp.com" {
+		t.Errorf("unexpected content. Received: %+v", c)
+	}
+	if len(c.Attachments) != 1 || c.Attachments[0].Name != "test.txt" || string(c.Attachments[0].Content) != "test attachment" {
+		t.Errorf("expected decoded attachment. Received: %+v", c.Attachments)
+	}
+}
+
+func TestMessagesInfoUnknown(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	_, err := m.Messages().Info("53108-unknown-message")
+	if !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("expected unknown message error. Received: %v", err)
+	}
+	_, err = m.Messages().Content("53108-unknown-message")
+	if !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("expected unknown message error. Received: %v", err)
+	}
+}
+
+func TestMessageContentHeaders(t *testing.T) {
+	var c MessageContent
+	err := json.Unmarshal([]byte(`{"ts": 1365190000, "headers": {"Subject": "Hi", "Received": ["from a", "from b"]}}`), &c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if c.Headers["Subject"] != "Hi" || c.Headers["Received"] != "from a, from b" {
+		t.Errorf("expected repeated headers to be joined. Received: %v", c.Headers)
+	}
+}
+
+func TestMessagesScheduled(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	to := "53109-scheduled@example.com"
+	sendAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		Subject:   "Test Scheduled",
+		To:        []Recipient{{Email: to}},
+	}
+	rr, err := m.Messages().Send(msg, false, "", &sendAt)
+	if err != nil || len(rr) != 1 {
+		t.Errorf("expected 1 response. Received: %+v, %v", rr, err)
+		return
+	}
+	if rr[0].Status != "scheduled" {
+		t.Errorf("expected scheduled status. Received: %+v", rr[0])
+		return
+	}
+	id := rr[0].Id
+
+	list, err := m.Messages().ListScheduled(to)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list) != 1 || list[0].Id != id || list[0].To != to || !list[0].SendAt.Equal(sendAt) {
+		t.Errorf("expected scheduled message. Received: %+v", list)
+	}
+
+	later := sendAt.Add(time.Hour)
+	sm, err := m.Messages().Reschedule(id, later)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if sm.Id != id || !sm.SendAt.Equal(later) {
+		t.Errorf("expected rescheduled message. Received: %+v", sm)
+	}
+
+	sm, err = m.Messages().CancelScheduled(id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if sm.Id != id {
+		t.Errorf("expected cancelled message. Received: %+v", sm)
+	}
+
+	list, err = m.Messages().ListScheduled(to)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list) != 0 {
+		t.Errorf("expected no scheduled messages. Received: %+v", list)
+	}
+
+	if _, err := m.Messages().CancelScheduled(id); !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("expected unknown message error. Received: %v", err)
+	}
+}
+
+const testRawMessage = "From: Test Sender <sender@example.com>\r\n" +
+	"To: accept@test.mandrillapp.com\r\n" +
+	"Subject: Test Send Raw\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"Test send raw\r\n"
+
+func TestScheduledMessageEmptyTimes(t *testing.T) {
+	var ret []ScheduledMessage
+	err := json.Unmarshal([]byte(`[{"_id": "a", "send_at": "2030-01-02 03:04:05"}, {"_id": "b", "created_at": "", "send_at": ""}]`), &ret)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ret) != 2 || !ret[0].CreatedAt.IsZero() || ret[0].SendAt.Year() != 2030 || !ret[1].SendAt.IsZero() {
+		t.Errorf("expected zero times for empty timestamps. Received: %+v", ret)
+	}
+}
+
+func TestMessagesTimeSeriesHour(t *testing.T) {
+	var ts MessagesTimeSeries
+	if err := json.Unmarshal([]byte(`{"time": "2030-01-02 03:00:00", "sent": 5}`), &ts); err != nil {
+		t.Error(err)
+		return
+	}
+	if ts.Time != "2030-01-02 03:00:00" || ts.Hour.Year() != 2030 || ts.Hour.Hour() != 3 || ts.Sent != 5 {
+		t.Errorf("expected raw time, hour and counters. Received: %+v", ts)
+	}
+}
+
+func TestMessagesSendRaw(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	rr, err := m.Messages().SendRaw(testRawMessage, &MessagesSendRawRequest{FromEmail: TestFromEmail})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(rr) != 1 || rr[0].Email != "accept@test.mandrillapp.com" || rr[0].Status == "rejected" {
+		t.Errorf("expected 1 accepted response. Received: %+v", rr)
+	}
+}
+
+func TestMessagesSendRawReader(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	r := &MessagesSendRawRequest{
+		FromEmail: TestFromEmail,
+		To:        []string{"accept@test.mandrillapp.com", "reject@test.mandrillapp.com"},
+	}
+	rr, err := m.Messages().SendRawReader(strings.NewReader(testRawMessage), r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(rr) != 2 {
+		t.Errorf("expected 2 responses. Received: %+v", rr)
+	}
+}
+
+func TestMessagesParse(t *testing.T) {
+	m := NewMandrill(TestAPIKey)
+	raw := "From: Test Sender <" + TestFromEmail + ">\r\n" +
+		"To: Test Recipient <accept@test.mandrillapp.com>\r\n" +
+		"Subject: Test Parse\r\n" +
+		"X-Test-Header: 53110\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n" +
+		"\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"Test parse\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		"<p>Test parse</p>\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"Content-Disposition: attachment; filename=test.bin\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"dGVzdCBhdHRhY2htZW50\r\n" +
+		"--outer--\r\n"
+	msg, err := m.Messages().Parse(raw)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if msg.Subject != "Test Parse" || msg.FromEmail != TestFromEmail || msg.FromName != "Test Sender" {
+		t.Errorf("unexpected sender or subject. Received: %+v", msg)
+	}
+	if len(msg.To) != 1 || msg.To[0].Email != "accept@test.mandrillapp.com" || msg.To[0].Name != "Test Recipient" {
+		t.Errorf("unexpected recipients. Received: %+v", msg.To)
+	}
+	if !strings.Contains(msg.Text, "Test parse") || !strings.Contains(msg.HTML, "<p>Test parse</p>") {
+		t.Errorf("unexpected content. Received text: %q, html: %q", msg.Text, msg.HTML)
+	}
+	if msg.Headers["X-Test-Header"] != "53110" || msg.Headers["Subject"] != "" {
+		t.Errorf("expected only X- headers. Received: %+v", msg.Headers)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Name != "test.bin" || msg.Attachments[0].Content != "dGVzdCBhdHRhY2htZW50" {
+		t.Errorf("unexpected attachments. Received: %+v", msg.Attachments)
+	}
+
+	if _, err := m.Messages().Send(msg, false, "", nil); err != nil {
+		t.Errorf("expected parsed message to be sendable. Received: %v", err)
+	}
+}
@@ -1,22 +1,27 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Metadata struct {
 	m *Mandrill
 }
 
-func (m *Metadata) List() ([]metadataResponse, error) {
-	var ret []metadataResponse
+func (m *Metadata) List() ([]MetadataResponse, error) {
+	return m.ListContext(context.Background())
+}
+
+// ListContext is like List but carries ctx through the request
+func (m *Metadata) ListContext(ctx context.Context) ([]MetadataResponse, error) {
+	var ret []MetadataResponse
 	data := simpleRequest{m.m.APIKey}
-	body, err := m.m.execute("/metadata/list.json", data)
+	body, err := m.m.execute(ctx, "/metadata/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/metadata/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -24,25 +29,30 @@ func (m *Metadata) List() ([]metadataResponse, error) {
 	return ret, nil
 }
 
-type metadataResponse struct {
+type MetadataResponse struct {
 	Name         string `json:"name"`
 	State        string `json:"state"`
 	ViewTemplate string `json:"view_template"`
 }
 
-func (m *Metadata) Add(name string, viewTemplate string) (metadataResponse, error) {
-	var ret metadataResponse
+func (m *Metadata) Add(name string, viewTemplate string) (MetadataResponse, error) {
+	return m.AddContext(context.Background(), name, viewTemplate)
+}
+
+// AddContext is like Add but carries ctx through the request
+func (m *Metadata) AddContext(ctx context.Context, name string, viewTemplate string) (MetadataResponse, error) {
+	var ret MetadataResponse
 	data := struct {
 		APIKey       string `json:"key"`
 		Name         string `json:"name"`
 		ViewTemplate string `json:"view_template,omitempty"`
 	}{m.m.APIKey, name, viewTemplate}
-	body, err := m.m.execute("/metadata/add.json", data)
+	body, err := m.m.execute(ctx, "/metadata/add.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/metadata/add.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -50,19 +60,24 @@ func (m *Metadata) Add(name string, viewTemplate string) (metadataResponse, erro
 	return ret, nil
 }
 
-func (m *Metadata) Update(name string, viewTemplate string) (metadataResponse, error) {
-	var ret metadataResponse
+func (m *Metadata) Update(name string, viewTemplate string) (MetadataResponse, error) {
+	return m.UpdateContext(context.Background(), name, viewTemplate)
+}
+
+// UpdateContext is like Update but carries ctx through the request
+func (m *Metadata) UpdateContext(ctx context.Context, name string, viewTemplate string) (MetadataResponse, error) {
+	var ret MetadataResponse
 	data := struct {
 		APIKey       string `json:"key"`
 		Name         string `json:"name"`
 		ViewTemplate string `json:"view_template"`
 	}{m.m.APIKey, name, viewTemplate}
-	body, err := m.m.execute("/metadata/update.json", data)
+	body, err := m.m.execute(ctx, "/metadata/update.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/metadata/update.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -70,18 +85,23 @@ func (m *Metadata) Update(name string, viewTemplate string) (metadataResponse, e
 	return ret, nil
 }
 
-func (m *Metadata) Delete(name string) (metadataResponse, error) {
-	var ret metadataResponse
+func (m *Metadata) Delete(name string) (MetadataResponse, error) {
+	return m.DeleteContext(context.Background(), name)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (m *Metadata) DeleteContext(ctx context.Context, name string) (MetadataResponse, error) {
+	var ret MetadataResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Name   string `json:"name"`
 	}{m.m.APIKey, name}
-	body, err := m.m.execute("/metadata/delete.json", data)
+	body, err := m.m.execute(ctx, "/metadata/delete.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/metadata/delete.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
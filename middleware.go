@@ -0,0 +1,84 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Request is an api call as seen by middleware
+type Request struct {
+	// the api path, e.g. "/messages/send.json"
+	Path string
+
+	// the JSON request body with the API key removed. Middleware may replace it
+	Body json.RawMessage
+}
+
+// Response is the result of an api call as seen by middleware
+type Response struct {
+	// the raw response body. Empty if the call failed
+	Body []byte
+
+	// the time taken by the call, including any retries
+	Duration time.Duration
+}
+
+// Handler performs an api call. The returned Response is never nil, even when err is not
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a Handler to observe or modify calls, e.g. for logging, auditing or metrics
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds mw to the middleware chain. The first middleware added is outermost
+func WithMiddleware(mw ...Middleware) Option {
+	return func(m *Mandrill) {
+		m.Middleware = append(m.Middleware, mw...)
+	}
+}
+
+// executeMiddleware runs the request body through the middleware chain. The API key is
+// stripped before the chain and restored just before sending
+func (m *Mandrill) executeMiddleware(ctx context.Context, path string, body []byte) ([]byte, error) {
+	redacted, err := setKey(body, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var h Handler = func(ctx context.Context, req *Request) (*Response, error) {
+		start := time.Now()
+		key, err := json.Marshal(m.APIKey)
+		if err != nil {
+			return &Response{}, err
+		}
+		body, err := setKey(req.Body, key)
+		if err != nil {
+			return &Response{}, err
+		}
+		respB, err := m.executeRetry(ctx, req.Path, body)
+		return &Response{Body: respB, Duration: time.Since(start)}, err
+	}
+	for i := len(m.Middleware) - 1; i >= 0; i-- {
+		h = m.Middleware[i](h)
+	}
+
+	resp, err := h(ctx, &Request{Path: path, Body: redacted})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// setKey returns the JSON object body with its "key" field set to key, or removed if key is nil
+func setKey(body []byte, key json.RawMessage) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if key == nil {
+		delete(fields, "key")
+	} else {
+		fields["key"] = key
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,82 @@
+package mandrill
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var received map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &received)
+		w.Write([]byte(`{"name":"test-tag"}`))
+	}))
+	defer ts.Close()
+
+	var order []string
+	var seen []*Request
+	var resp *Response
+	outer := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			order = append(order, "outer")
+			seen = append(seen, &Request{Path: req.Path, Body: append(json.RawMessage(nil), req.Body...)})
+			r, err := next(ctx, req)
+			resp = r
+			return r, err
+		}
+	}
+	inner := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			order = append(order, "inner")
+			req.Body = json.RawMessage(`{"tag":"rewritten-tag"}`)
+			return next(ctx, req)
+		}
+	}
+
+	m := NewMandrill("secret-key", WithBaseURL(ts.URL), WithMiddleware(outer, inner))
+	if _, err := m.Tags().Info("test-tag"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("expected outer then inner middleware. Received: %v", order)
+	}
+	if len(seen) != 1 || seen[0].Path != "/tags/info.json" || strings.Contains(string(seen[0].Body), "secret-key") {
+		t.Errorf("expected redacted request. Received: %s", seen[0].Body)
+	}
+	if received["key"] != "secret-key" || received["tag"] != "rewritten-tag" {
+		t.Errorf("expected key restored and body rewritten. Received: %v", received)
+	}
+	if resp == nil || string(resp.Body) != `{"name":"test-tag"}` || resp.Duration <= 0 {
+		t.Errorf("expected raw response with duration. Received: %+v", resp)
+	}
+}
+
+func TestMiddlewareError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`))
+	}))
+	defer ts.Close()
+
+	var seenErr error
+	mw := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			r, err := next(ctx, req)
+			seenErr = err
+			return r, err
+		}
+	}
+	m := NewMandrill("secret-key", WithBaseURL(ts.URL), WithMiddleware(mw))
+	_, err := m.Users().Ping()
+	if ae, ok := seenErr.(*APIError); !ok || ae.Name != "Invalid_Key" || err != seenErr {
+		t.Errorf("expected middleware to see invalid key error. Received: %v", seenErr)
+	}
+}
@@ -0,0 +1,243 @@
+package mandrill
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mimePart is a rendered part of a MIME document
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// ToMIME renders msg as an RFC 5322 document suitable for Messages.SendRaw or Inbound.SendRaw.
+// The text and html are sent as multipart/alternative, images as multipart/related and
+// attachments as multipart/mixed, using only the structure needed. Bcc recipients and
+// BCCAddress are left out of the headers; include them in the envelope, see Recipients.
+// Header names in msg.Headers are canonicalized, and names that are not valid field names,
+// names of headers ToMIME writes itself, such as Subject, or values containing line breaks
+// are rejected. Long header lines are folded
+func (msg *Message) ToMIME() ([]byte, error) {
+	content, err := msg.contentPart()
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.Images) > 0 {
+		parts := []*mimePart{content}
+		for _, img := range msg.Images {
+			p, err := binaryPart(img.Name, img.Type, img.Content, true)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, p)
+		}
+		if content, err = multipartPart("related", parts); err != nil {
+			return nil, err
+		}
+	}
+	if len(msg.Attachments) > 0 {
+		parts := []*mimePart{content}
+		for _, a := range msg.Attachments {
+			p, err := binaryPart(a.Name, a.Type, a.Content, false)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, p)
+		}
+		if content, err = multipartPart("mixed", parts); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(k, v string) {
+		buf.WriteString(foldHeader(k, v, false))
+	}
+	from := mail.Address{Name: msg.FromName, Address: msg.FromEmail}
+	writeHeader("From", from.String())
+	var to, cc []string
+	for _, r := range msg.To {
+		a := mail.Address{Name: r.Name, Address: r.Email}
+		switch r.Type {
+		case "", RecipientTo:
+			to = append(to, a.String())
+		case RecipientCC:
+			cc = append(cc, a.String())
+		}
+	}
+	if len(to) > 0 {
+		writeHeader("To", strings.Join(to, ", "))
+	}
+	if len(cc) > 0 {
+		writeHeader("Cc", strings.Join(cc, ", "))
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-Id", messageId(msg.FromEmail))
+	writeHeader("MIME-Version", "1.0")
+
+	keys := make([]string, 0, len(msg.Headers))
+	for k := range msg.Headers {
+		if err := checkHeader(k, msg.Headers[k]); err != nil {
+			return nil, err
+		}
+		if generatedHeaders[textproto.CanonicalMIMEHeaderKey(k)] {
+			return nil, fmt.Errorf("mandrill: header %s is set from the message and cannot be in Headers", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeHeader(textproto.CanonicalMIMEHeaderKey(k), mime.QEncoding.Encode("utf-8", msg.Headers[k]))
+	}
+	for _, k := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+		if v := content.header.Get(k); v != "" {
+			writeHeader(k, v)
+		}
+	}
+	buf.WriteString("\r\n")
+	buf.Write(content.body)
+	return buf.Bytes(), nil
+}
+
+// generatedHeaders are the headers ToMIME writes from the message itself
+var generatedHeaders = map[string]bool{
+	"From":                      true,
+	"To":                        true,
+	"Cc":                        true,
+	"Subject":                   true,
+	"Date":                      true,
+	"Message-Id":                true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+}
+
+// checkHeader returns an error if name is not an RFC 5322 field name or value contains a line break
+func checkHeader(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("mandrill: empty header name")
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c < 33 || c > 126 || c == ':' {
+			return fmt.Errorf("mandrill: invalid header name %q", name)
+		}
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("mandrill: header %s contains a line break", name)
+	}
+	return nil
+}
+
+// Recipients returns the envelope recipients of msg: every address in To, whatever its type,
+// and BCCAddress if set
+func (msg *Message) Recipients() []string {
+	ret := make([]string, 0, len(msg.To)+1)
+	for _, r := range msg.To {
+		ret = append(ret, r.Email)
+	}
+	if msg.BCCAddress != "" {
+		ret = append(ret, msg.BCCAddress)
+	}
+	return ret
+}
+
+// contentPart returns the text and html as a single part, or multipart/alternative if both are set
+func (msg *Message) contentPart() (*mimePart, error) {
+	var parts []*mimePart
+	if msg.Text != "" || msg.HTML == "" {
+		parts = append(parts, textPart("text/plain", msg.Text))
+	}
+	if msg.HTML != "" {
+		parts = append(parts, textPart("text/html", msg.HTML))
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return multipartPart("alternative", parts)
+}
+
+func textPart(mediaType, content string) *mimePart {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	io.WriteString(w, content)
+	w.Close()
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", mime.FormatMediaType(mediaType, map[string]string{"charset": "utf-8"}))
+	h.Set("Content-Transfer-Encoding", "quoted-printable")
+	return &mimePart{h, buf.Bytes()}
+}
+
+// binaryPart returns an attachment, or an inline image if inline is set, from base64 content
+func binaryPart(name, mediaType, content string, inline bool) (*mimePart, error) {
+	b, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("mandrill: content of %q is not valid base64: %s", name, err)
+	}
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", mime.FormatMediaType(mediaType, map[string]string{"name": name}))
+	h.Set("Content-Transfer-Encoding", "base64")
+	if inline {
+		h.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": name}))
+		h.Set("Content-Id", "<"+name+">")
+	} else {
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	}
+
+	// rewrap the content in 76 character lines
+	encoded := base64.StdEncoding.EncodeToString(b)
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return &mimePart{h, buf.Bytes()}, nil
+}
+
+// multipartPart returns parts as a multipart/subtype part
+func multipartPart(subtype string, parts []*mimePart) (*mimePart, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		pw, err := w.CreatePart(p.header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write(p.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()}))
+	return &mimePart{h, buf.Bytes()}, nil
+}
+
+// messageId returns a new random Message-Id in the domain of from
+func messageId(from string) string {
+	domain := "mandrill.local"
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		domain = from[i+1:]
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("<%x@%s>", b, domain)
+}
@@ -0,0 +1,176 @@
+package mandrill
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func testMIMEMessage() *Message {
+	return &Message{
+		FromEmail: TestFromEmail,
+		FromName:  "Tëst Sender",
+		Subject:   "Tëst ToMIME",
+		Text:      "Test text",
+		HTML:      `<p>Test html</p><img src="cid:logo.png">`,
+		To: []Recipient{
+			{Email: "accept@test.mandrillapp.com", Name: "Test Recipient"},
+			{Email: "cc@example.com", Type: RecipientCC},
+			{Email: "bcc@example.com", Type: RecipientBCC},
+		},
+		BCCAddress:  "archive@example.com",
+		Headers:     map[string]string{"X-Test-Header": "53111", "Reply-To": "reply@example.com"},
+		Images:      []*Image{{Name: "logo.png", Type: "image/png", Content: base64.StdEncoding.EncodeToString(testPNG)}},
+		Attachments: []*Attachment{{Name: "test.txt", Type: "text/plain", Content: "dGVzdCBhdHRhY2htZW50"}},
+	}
+}
+
+func TestToMIME(t *testing.T) {
+	raw, err := testMIMEMessage().ToMIME()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	dec := new(mime.WordDecoder)
+	if subject, _ := dec.DecodeHeader(m.Header.Get("Subject")); subject != "Tëst ToMIME" {
+		t.Errorf("unexpected subject. Received: %q", subject)
+	}
+	if from, err := m.Header.AddressList("From"); err != nil || from[0].Name != "Tëst Sender" {
+		t.Errorf("unexpected from. Received: %v, %v", from, err)
+	}
+	if m.Header.Get("Cc") != "<cc@example.com>" || m.Header.Get("Bcc") != "" || strings.Contains(string(raw), "bcc@example.com") {
+		t.Errorf("expected cc header and no bcc recipients. Received: %q", raw)
+	}
+	if m.Header.Get("X-Test-Header") != "53111" || m.Header.Get("Reply-To") != "reply@example.com" {
+		t.Errorf("expected custom headers. Received: %v", m.Header)
+	}
+
+	// mixed(related(alternative(text, html), image), attachment)
+	var types []string
+	var walk func(contentType string, body []byte)
+	walk = func(contentType string, body []byte) {
+		mediaType, params, _ := mime.ParseMediaType(contentType)
+		types = append(types, mediaType)
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			return
+		}
+		r := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			p, err := r.NextRawPart()
+			if err != nil {
+				return
+			}
+			b, _ := ioutil.ReadAll(p)
+			walk(p.Header.Get("Content-Type"), b)
+		}
+	}
+	body, _ := ioutil.ReadAll(m.Body)
+	walk(m.Header.Get("Content-Type"), body)
+	expected := "multipart/mixed multipart/related multipart/alternative text/plain text/html image/png text/plain"
+	if strings.Join(types, " ") != expected {
+		t.Errorf("unexpected structure. Received: %v", types)
+	}
+}
+
+func TestToMIMEParse(t *testing.T) {
+	msg := testMIMEMessage()
+	raw, err := msg.ToMIME()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	m := NewMandrill(TestAPIKey)
+	parsed, err := m.Messages().Parse(string(raw))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if parsed.Subject != msg.Subject || parsed.Text != msg.Text || parsed.HTML != msg.HTML {
+		t.Errorf("unexpected parsed content. Received: %+v", parsed)
+	}
+	if len(parsed.Images) != 1 || parsed.Images[0].Name != "logo.png" || parsed.Images[0].Content != msg.Images[0].Content {
+		t.Errorf("unexpected parsed images. Received: %+v", parsed.Images)
+	}
+	if len(parsed.Attachments) != 1 || parsed.Attachments[0].Content != msg.Attachments[0].Content {
+		t.Errorf("unexpected parsed attachments. Received: %+v", parsed.Attachments)
+	}
+
+	rr, err := m.Messages().SendRaw(string(raw), &MessagesSendRawRequest{To: msg.Recipients()})
+	if err != nil || len(rr) != 4 {
+		t.Errorf("expected 4 responses. Received: %+v, %v", rr, err)
+	}
+}
+
+func TestToMIMEInvalidContent(t *testing.T) {
+	msg := &Message{FromEmail: TestFromEmail, Attachments: []*Attachment{{Name: "bad", Content: "not base64!"}}}
+	if _, err := msg.ToMIME(); err == nil {
+		t.Errorf("expected error for invalid attachment content")
+	}
+}
+
+func TestToMIMEHeaders(t *testing.T) {
+	msg := &Message{FromEmail: TestFromEmail, Text: "Test", Headers: map[string]string{"x-lower-case": "1"}}
+	raw, err := msg.ToMIME()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(string(raw), "\r\nX-Lower-Case: 1\r\n") {
+		t.Errorf("expected canonical header name. Received: %s", raw)
+	}
+
+	for k, v := range map[string]string{
+		"X-Inject":     "1\r\nBcc: victim@example.com",
+		"X-Newline":    "1\nX-Other: 2",
+		"X Space":      "1",
+		"X-Colon:":     "1",
+		"X-Ünicode":    "1",
+		"X-Inject\r\n": "1",
+		"subject":      "dup",
+		"message-id":   "<x@y>",
+		"MIME-Version": "1.0",
+	} {
+		msg.Headers = map[string]string{k: v}
+		if _, err := msg.ToMIME(); err == nil {
+			t.Errorf("expected error for header %q: %q", k, v)
+		}
+	}
+}
+
+func TestToMIMEFoldsHeaders(t *testing.T) {
+	long := strings.Repeat("Grüße aus Zürich ", 12)
+	msg := &Message{FromEmail: TestFromEmail, Subject: long, Text: "Test", Headers: map[string]string{"X-Note": long}}
+	raw, err := msg.ToMIME()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	lines := strings.Split(string(raw[:bytes.Index(raw, []byte("\r\n\r\n"))]), "\r\n")
+	for _, line := range lines {
+		if len(line) > 100 {
+			t.Errorf("expected header lines to be folded. Received %d characters: %s", len(line), line)
+		}
+	}
+
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	dec := new(mime.WordDecoder)
+	for _, k := range []string{"Subject", "X-Note"} {
+		if v, err := dec.DecodeHeader(m.Header.Get(k)); err != nil || v != long {
+			t.Errorf("expected %s to round trip. Received: %q, %v", k, v, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package mandrill
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a Mandrill created by NewMandrill
+type Option func(*Mandrill)
+
+// WithBaseURL sends requests to url instead of APIBaseURL
+func WithBaseURL(url string) Option {
+	return func(m *Mandrill) {
+		m.BaseURL = url
+	}
+}
+
+// WithHTTPClient sends requests using c. Its timeout is left as is
+func WithHTTPClient(c *http.Client) Option {
+	return func(m *Mandrill) {
+		m.HttpClient = c
+	}
+}
+
+// WithTransport sends requests through rt, keeping any previously configured timeout
+func WithTransport(rt http.RoundTripper) Option {
+	return func(m *Mandrill) {
+		c := m.httpClientCopy()
+		c.Transport = rt
+		m.HttpClient = c
+	}
+}
+
+// WithTimeout limits the time taken by each request, including reading the response.
+// A zero timeout means no timeout
+func WithTimeout(d time.Duration) Option {
+	return func(m *Mandrill) {
+		c := m.httpClientCopy()
+		c.Timeout = d
+		m.HttpClient = c
+	}
+}
+
+// WithUserAgent appends suffix to the User-Agent header sent with each request
+func WithUserAgent(suffix string) Option {
+	return func(m *Mandrill) {
+		m.UserAgent = suffix
+	}
+}
+
+// WithValidation runs Message.Validate before each Send and SendTemplate, so that invalid
+// messages fail without a round trip
+func WithValidation() Option {
+	return func(m *Mandrill) {
+		m.ValidateMessages = true
+	}
+}
+
+// httpClientCopy returns a copy of the configured client so options never
+// modify a client shared with the caller
+func (m *Mandrill) httpClientCopy() *http.Client {
+	if m.HttpClient == nil {
+		return &http.Client{Timeout: DefaultTimeout}
+	}
+	c := *m.HttpClient
+	return &c
+}
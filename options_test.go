@@ -0,0 +1,61 @@
+package mandrill
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithBaseURL(t *testing.T) {
+	var path, ua string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		ua = r.Header.Get("User-Agent")
+		w.Write([]byte(`"PONG!"`))
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL+"/api/1.0/"), WithUserAgent("test/1.0"))
+	ok, err := m.Users().Ping()
+	if err != nil || !ok {
+		t.Errorf("expected pong. Received: %v, %s", ok, err)
+		return
+	}
+
+	if path != "/api/1.0/users/ping.json" {
+		t.Errorf("expected path /api/1.0/users/ping.json. Received: %s", path)
+	}
+	if ua != "Mandrill Go test/1.0" {
+		t.Errorf("expected user agent with suffix. Received: %s", ua)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`"PONG!"`))
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL), WithTimeout(20*time.Millisecond))
+	if _, err := m.Users().Ping(); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	shared := &http.Client{Timeout: time.Minute}
+	m := NewMandrill("dummy-key", WithHTTPClient(shared), WithTransport(http.DefaultTransport))
+	if m.HttpClient == shared || shared.Transport != nil {
+		t.Error("expected shared client to be left unmodified")
+	}
+	if m.HttpClient.Timeout != time.Minute || m.HttpClient.Transport != http.DefaultTransport {
+		t.Errorf("expected transport with existing timeout. Received: %+v", m.HttpClient)
+	}
+
+	m = NewMandrill("dummy-key", WithTransport(http.DefaultTransport))
+	if m.HttpClient.Timeout != DefaultTimeout {
+		t.Errorf("expected default timeout. Received: %s", m.HttpClient.Timeout)
+	}
+}
@@ -0,0 +1,474 @@
+package mandrill
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrOutboxClosed is returned by Enqueue after Shutdown
+var ErrOutboxClosed = errors.New("mandrill: outbox is shut down")
+
+// DefaultOutboxRetryPolicy retries failed sends for about an hour
+var DefaultOutboxRetryPolicy = RetryPolicy{
+	MaxAttempts:    12,
+	InitialBackoff: time.Second,
+	MaxBackoff:     10 * time.Minute,
+}
+
+// OutboxEntry is a message waiting in an outbox, with its send options
+type OutboxEntry struct {
+	Id        string     `json:"id"`
+	Message   *Message   `json:"message"`
+	Async     bool       `json:"async,omitempty"`
+	IPPool    string     `json:"ip_pool,omitempty"`
+	SendAt    *time.Time `json:"send_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// the number of failed attempts so far and the error from the last one
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+
+	// when the entry is next due to be sent
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// OutboxStore persists outbox entries. Implementations must be safe for concurrent use
+type OutboxStore interface {
+	// Save creates or replaces a pending entry
+	Save(e *OutboxEntry) error
+
+	// Delete removes a pending entry. Deleting a missing entry is not an error
+	Delete(id string) error
+
+	// Pending returns the pending entries, oldest first
+	Pending() ([]*OutboxEntry, error)
+
+	// DeadLetter moves a pending entry to the dead letters
+	DeadLetter(e *OutboxEntry) error
+
+	// DeadLetters returns the entries that could not be sent, oldest first
+	DeadLetters() ([]*OutboxEntry, error)
+}
+
+// Outbox queues messages in a persistent store and sends them in the background, retrying
+// failures. Messages that fail with a permanent api error, or run out of attempts, are moved
+// to the store's dead letters. Sending is at least once: a message whose send timed out
+// may be sent again
+type Outbox struct {
+	// Sender sends queued messages, e.g. m.Messages()
+	Sender MessageSender
+
+	Store OutboxStore
+
+	// the number of messages sent at once, 4 if zero
+	Workers int
+
+	// Retry controls the delay between attempts and their number. A MaxAttempts
+	// below 1 retries transient failures without limit. The zero value uses
+	// DefaultOutboxRetryPolicy
+	Retry RetryPolicy
+
+	// OnSent, if set, is called after each message is sent
+	OnSent func(e *OutboxEntry, responses []SendResponse)
+
+	// OnDeadLetter, if set, is called after a message is moved to the dead letters
+	OnDeadLetter func(e *OutboxEntry, err error)
+
+	// OnError, if set, is called when the store fails to delete a sent entry, save a retry
+	// or move an entry to the dead letters. An entry that could not be deleted is sent
+	// again when the outbox is next started
+	OnError func(e *OutboxEntry, err error)
+
+	mu       sync.Mutex
+	queue    []*OutboxEntry
+	changed  chan struct{}
+	started  bool
+	draining bool
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewOutbox returns an Outbox sending through sender, using DefaultOutboxRetryPolicy
+func NewOutbox(sender MessageSender, store OutboxStore) *Outbox {
+	return &Outbox{Sender: sender, Store: store, Retry: DefaultOutboxRetryPolicy}
+}
+
+// Enqueue stores message for sending, returning its entry id once it is persisted.
+// It may be called before Start
+func (o *Outbox) Enqueue(message *Message, async bool, ipPool string, sendAt *time.Time) (string, error) {
+	if message == nil {
+		return "", errors.New("mandrill: no message to send")
+	}
+	now := time.Now()
+	e := &OutboxEntry{
+		Id:          newOutboxId(),
+		Message:     message,
+		Async:       async,
+		IPPool:      ipPool,
+		SendAt:      sendAt,
+		CreatedAt:   now,
+		NextAttempt: now,
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.draining {
+		return "", ErrOutboxClosed
+	}
+	if err := o.Store.Save(e); err != nil {
+		return "", err
+	}
+	o.push(e)
+	return e.Id, nil
+}
+
+// Start loads the pending entries from the store and starts the workers
+func (o *Outbox) Start() error {
+	pending, err := o.Store.Pending()
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.started {
+		return errors.New("mandrill: outbox already started")
+	}
+	o.started = true
+	queued := make(map[string]bool, len(o.queue))
+	for _, e := range o.queue {
+		queued[e.Id] = true
+	}
+	for _, e := range pending {
+		if !queued[e.Id] {
+			o.push(e)
+		}
+	}
+
+	o.ctx, o.cancel = context.WithCancel(context.Background())
+	workers := o.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+	for i := 0; i < workers; i++ {
+		o.wg.Add(1)
+		go o.work()
+	}
+	return nil
+}
+
+// Shutdown stops accepting messages and waits for the workers to send every message that
+// is due, leaving those waiting for a retry in the store. If ctx ends first, sends in
+// progress are cancelled and ctx's error is returned
+func (o *Outbox) Shutdown(ctx context.Context) error {
+	o.mu.Lock()
+	o.draining = true
+	started := o.started
+	o.notify()
+	o.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		o.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		o.cancel()
+		return nil
+	case <-ctx.Done():
+		o.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// push adds e to the queue and wakes the workers. Called with o.mu held
+func (o *Outbox) push(e *OutboxEntry) {
+	o.queue = append(o.queue, e)
+	o.notify()
+}
+
+// notify wakes the workers. Called with o.mu held
+func (o *Outbox) notify() {
+	if o.changed != nil {
+		close(o.changed)
+	}
+	o.changed = make(chan struct{})
+}
+
+// next removes and returns the earliest due entry, or returns the time until the earliest
+// entry is due and a channel closed when the queue changes
+func (o *Outbox) next() (*OutboxEntry, time.Duration, <-chan struct{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.changed == nil {
+		o.changed = make(chan struct{})
+	}
+	earliest := -1
+	for i, e := range o.queue {
+		if earliest < 0 || e.NextAttempt.Before(o.queue[earliest].NextAttempt) {
+			earliest = i
+		}
+	}
+	if earliest < 0 {
+		return nil, -1, o.changed
+	}
+	e := o.queue[earliest]
+	wait := time.Until(e.NextAttempt)
+	if wait > 0 {
+		return nil, wait, o.changed
+	}
+	o.queue = append(o.queue[:earliest], o.queue[earliest+1:]...)
+	return e, 0, nil
+}
+
+func (o *Outbox) work() {
+	defer o.wg.Done()
+	for {
+		e, wait, changed := o.next()
+		if e != nil {
+			o.send(e)
+			continue
+		}
+
+		o.mu.Lock()
+		draining := o.draining
+		o.mu.Unlock()
+		if draining {
+			return
+		}
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-changed:
+		case <-due:
+		case <-o.ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if o.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// send attempts to send e, then removes it, retries it later or dead letters it
+func (o *Outbox) send(e *OutboxEntry) {
+	rr, err := o.Sender.SendContext(o.ctx, e.Message, e.Async, e.IPPool, e.SendAt)
+	if err == nil {
+		if err := o.Store.Delete(e.Id); err != nil {
+			o.storeError(e, fmt.Errorf("mandrill: deleting sent outbox entry %s: %w", e.Id, err))
+		}
+		if o.OnSent != nil {
+			o.OnSent(e, rr)
+		}
+		return
+	}
+
+	if o.ctx.Err() != nil {
+		// cancelled by Shutdown: the entry stays pending in the store
+		return
+	}
+	e.Attempts++
+	e.LastError = err.Error()
+	retry := o.retryPolicy()
+	if permanentError(err) || (retry.MaxAttempts > 0 && e.Attempts >= retry.MaxAttempts) {
+		derr := o.Store.DeadLetter(e)
+		if derr == nil {
+			if o.OnDeadLetter != nil {
+				o.OnDeadLetter(e, err)
+			}
+			return
+		}
+		o.storeError(e, fmt.Errorf("mandrill: dead lettering outbox entry %s: %w", e.Id, derr))
+	}
+
+	e.NextAttempt = time.Now().Add(retry.backoff(e.Attempts))
+	if err := o.Store.Save(e); err != nil {
+		o.storeError(e, fmt.Errorf("mandrill: saving outbox entry %s: %w", e.Id, err))
+	}
+	o.mu.Lock()
+	o.push(e)
+	o.mu.Unlock()
+}
+
+// storeError passes err to OnError, if set
+func (o *Outbox) storeError(e *OutboxEntry, err error) {
+	if o.OnError != nil {
+		o.OnError(e, err)
+	}
+}
+
+// retryPolicy returns o.Retry, or DefaultOutboxRetryPolicy if it is the zero value
+func (o *Outbox) retryPolicy() RetryPolicy {
+	if o.Retry == (RetryPolicy{}) {
+		return DefaultOutboxRetryPolicy
+	}
+	return o.Retry
+}
+
+// permanentError reports whether a send that failed with err would fail again
+func permanentError(err error) bool {
+	var me *MessageError
+	if errors.As(err, &me) {
+		return true
+	}
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.Name != "GeneralError" && ae.HTTPStatus != 429
+	}
+	return false
+}
+
+func newOutboxId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// FileStore is an OutboxStore keeping each entry in a JSON file, under
+// pending and dead directories
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore returns a FileStore in dir, creating it if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	for _, sub := range []string{"pending", "dead"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, err
+		}
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (f *FileStore) Save(e *OutboxEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.write("pending", e)
+}
+
+func (f *FileStore) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.remove("pending", id)
+}
+
+func (f *FileStore) Pending() ([]*OutboxEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.read("pending")
+}
+
+func (f *FileStore) DeadLetter(e *OutboxEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.write("dead", e); err != nil {
+		return err
+	}
+	return f.remove("pending", e.Id)
+}
+
+func (f *FileStore) DeadLetters() ([]*OutboxEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.read("dead")
+}
+
+// path returns the file for entry id in sub, rejecting ids that are not plain file names
+func (f *FileStore) path(sub, id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("mandrill: invalid outbox entry id %q", id)
+	}
+	return filepath.Join(f.dir, sub, id+".json"), nil
+}
+
+// write saves e in sub
+func (f *FileStore) write(sub string, e *OutboxEntry) error {
+	path, err := f.path(sub, e.Id)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, e)
+}
+
+// writeFileAtomic saves v as JSON in path by writing a temporary file in the same
+// directory and renaming it, so that readers never see a partial file
+func writeFileAtomic(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FileStore) remove(sub, id string) error {
+	path, err := f.path(sub, id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (f *FileStore) read(sub string) ([]*OutboxEntry, error) {
+	files, err := filepath.Glob(filepath.Join(f.dir, sub, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*OutboxEntry, 0, len(files))
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var e OutboxEntry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, fmt.Errorf("mandrill: reading outbox entry %s: %s", file, err)
+		}
+		ret = append(ret, &e)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].CreatedAt.Before(ret[j].CreatedAt) })
+	return ret, nil
+}
@@ -0,0 +1,259 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/mandrill/mandrilltest"
+)
+
+func testOutbox(t *testing.T, s *mandrilltest.Server) (*Outbox, string) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+	o := NewOutbox(m.Messages(), store)
+	o.Workers = 2
+	o.Retry = testRetryPolicy
+	return o, dir
+}
+
+func TestOutbox(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	o, dir := testOutbox(t, s)
+	defer os.RemoveAll(dir)
+
+	sent := make(chan string, 3)
+	o.OnSent = func(e *OutboxEntry, rr []SendResponse) { sent <- e.Id }
+	if err := o.Start(); err != nil {
+		t.Error(err)
+		return
+	}
+	for i := 0; i < 3; i++ {
+		msg := &Message{FromEmail: TestFromEmail, Subject: "Test Outbox", To: []Recipient{{Email: "outbox@example.com"}}}
+		if _, err := o.Enqueue(msg, false, "", nil); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := o.Shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if len(sent) != 3 || len(s.Messages()) != 3 {
+		t.Errorf("expected 3 messages sent. Received: %d", len(s.Messages()))
+	}
+	if pending, _ := o.Store.Pending(); len(pending) != 0 {
+		t.Errorf("expected empty store. Received: %d entries", len(pending))
+	}
+
+	if _, err := o.Enqueue(&Message{}, false, "", nil); err != ErrOutboxClosed {
+		t.Errorf("expected ErrOutboxClosed. Received: %v", err)
+	}
+}
+
+func TestOutboxRestart(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	o, dir := testOutbox(t, s)
+	defer os.RemoveAll(dir)
+
+	// entries enqueued by an outbox that was never started are sent by the next one
+	msg := &Message{FromEmail: TestFromEmail, Subject: "Test Outbox", To: []Recipient{{Email: "outbox@example.com"}}}
+	id, err := o.Enqueue(msg, false, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	store, _ := NewFileStore(dir)
+	pending, err := store.Pending()
+	if err != nil || len(pending) != 1 || pending[0].Id != id || pending[0].Message.Subject != "Test Outbox" {
+		t.Errorf("expected stored entry %s. Received: %+v, %v", id, pending, err)
+		return
+	}
+
+	o2 := NewOutbox(o.Sender, store)
+	if err := o2.Start(); err != nil {
+		t.Error(err)
+		return
+	}
+	o2.Shutdown(context.Background())
+	if len(s.Messages()) != 1 {
+		t.Errorf("expected 1 message sent. Received: %d", len(s.Messages()))
+	}
+}
+
+func TestOutboxRetry(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	s.AddFault(mandrilltest.Fault{Path: "/messages/send.json", Times: 1, Status: 503})
+	o, dir := testOutbox(t, s)
+	defer os.RemoveAll(dir)
+
+	sent := make(chan *OutboxEntry, 1)
+	o.OnSent = func(e *OutboxEntry, rr []SendResponse) { sent <- e }
+	o.Start()
+	msg := &Message{FromEmail: TestFromEmail, Subject: "Test Outbox", To: []Recipient{{Email: "outbox@example.com"}}}
+	o.Enqueue(msg, false, "", nil)
+	select {
+	case e := <-sent:
+		if e.Attempts != 1 || e.LastError == "" {
+			t.Errorf("expected 1 failed attempt. Received: %d, %q", e.Attempts, e.LastError)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected message to be sent after retry")
+	}
+	o.Shutdown(context.Background())
+}
+
+func TestOutboxDeadLetter(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	o, dir := testOutbox(t, s)
+	defer os.RemoveAll(dir)
+
+	var deadErr error
+	o.OnDeadLetter = func(e *OutboxEntry, err error) { deadErr = err }
+	o.Start()
+	msg := &Message{FromEmail: TestFromEmail, SubAccount: "unknown", To: []Recipient{{Email: "outbox@example.com"}}}
+	id, _ := o.Enqueue(msg, false, "", nil)
+	o.Shutdown(context.Background())
+
+	if !errors.Is(deadErr, ErrUnknownSubaccount) {
+		t.Errorf("expected ErrUnknownSubaccount. Received: %v", deadErr)
+	}
+	dead, err := o.Store.DeadLetters()
+	if err != nil || len(dead) != 1 || dead[0].Id != id || dead[0].Attempts != 1 {
+		t.Errorf("expected dead letter %s. Received: %+v, %v", id, dead, err)
+	}
+	if pending, _ := o.Store.Pending(); len(pending) != 0 {
+		t.Errorf("expected no pending entries. Received: %d", len(pending))
+	}
+}
+
+func TestOutboxShutdownLeavesRetries(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	s.AddFault(mandrilltest.Fault{Path: "/messages/send.json", Status: 503})
+	o, dir := testOutbox(t, s)
+	defer os.RemoveAll(dir)
+	o.Retry = RetryPolicy{InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	o.Start()
+	msg := &Message{FromEmail: TestFromEmail, To: []Recipient{{Email: "outbox@example.com"}}}
+	id, _ := o.Enqueue(msg, false, "", nil)
+	for len(s.Requests()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := o.Shutdown(ctx); err != nil {
+		t.Error(err)
+	}
+	pending, err := o.Store.Pending()
+	if err != nil || len(pending) != 1 || pending[0].Id != id || pending[0].Attempts != 1 {
+		t.Errorf("expected entry %s pending retry. Received: %+v, %v", id, pending, err)
+	}
+}
+
+func TestFileStoreInvalidId(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "outbox")
+	defer os.RemoveAll(dir)
+	store, _ := NewFileStore(dir)
+	if err := store.Save(&OutboxEntry{Id: "../escape"}); err == nil {
+		t.Error("expected error for id with path separator")
+	}
+}
+
+func TestOutboxZeroValue(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	s.AddFault(mandrilltest.Fault{Path: "/messages/send.json", Times: 100, Status: 503})
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, _ := NewFileStore(dir)
+
+	// a zero Retry waits between attempts as DefaultOutboxRetryPolicy does
+	m := NewMandrill(mandrilltest.APIKey, WithBaseURL(s.URL))
+	o := &Outbox{Sender: m.Messages(), Store: store}
+	o.Start()
+	msg := &Message{FromEmail: TestFromEmail, Subject: "Test Outbox", To: []Recipient{{Email: "outbox@example.com"}}}
+	o.Enqueue(msg, false, "", nil)
+	time.Sleep(200 * time.Millisecond)
+	o.Shutdown(context.Background())
+
+	if n := len(s.Requests()); n != 1 {
+		t.Errorf("expected 1 request before the first retry. Received: %d", n)
+	}
+	pending, _ := store.Pending()
+	if len(pending) != 1 || pending[0].Attempts != 1 || time.Until(pending[0].NextAttempt) <= 0 {
+		t.Errorf("expected entry waiting for a retry. Received: %+v", pending)
+	}
+}
+
+// failingStore fails to delete entries and to save them after a failed attempt
+type failingStore struct {
+	OutboxStore
+}
+
+var errTestStore = errors.New("store unavailable")
+
+func (f failingStore) Save(e *OutboxEntry) error {
+	if e.Attempts > 0 {
+		return errTestStore
+	}
+	return f.OutboxStore.Save(e)
+}
+
+func (f failingStore) Delete(id string) error {
+	return errTestStore
+}
+
+func TestOutboxStoreErrors(t *testing.T) {
+	s := mandrilltest.NewServer()
+	defer s.Close()
+	s.AddFault(mandrilltest.Fault{Path: "/messages/send.json", Times: 1, Status: 503})
+	o, dir := testOutbox(t, s)
+	defer os.RemoveAll(dir)
+	o.Store = failingStore{o.Store}
+
+	var mu sync.Mutex
+	var errs []error
+	o.OnError = func(e *OutboxEntry, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	sent := make(chan *OutboxEntry, 1)
+	o.OnSent = func(e *OutboxEntry, rr []SendResponse) { sent <- e }
+	o.Start()
+	msg := &Message{FromEmail: TestFromEmail, Subject: "Test Outbox", To: []Recipient{{Email: "outbox@example.com"}}}
+	o.Enqueue(msg, false, "", nil)
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Error("expected message to be sent after retry")
+	}
+	o.Shutdown(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 2 || !errors.Is(errs[0], errTestStore) || !strings.Contains(errs[0].Error(), "saving") ||
+		!errors.Is(errs[1], errTestStore) || !strings.Contains(errs[1].Error(), "deleting") {
+		t.Errorf("expected save and delete errors. Received: %v", errs)
+	}
+}
@@ -1,7 +1,7 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Rejects struct {
@@ -12,20 +12,25 @@ type Rejects struct {
 // email: an email address to block
 // comment: an optional comment describing the rejection
 // subaccount:  an optional unique identifier for the subaccount to limit the blacklist entry maxlength(255)
-func (r *Rejects) Add(email string, comment string, subaccount string) (rejectsAddResponse, error) {
-	var ret rejectsAddResponse
+func (r *Rejects) Add(email string, comment string, subaccount string) (RejectsAddResponse, error) {
+	return r.AddContext(context.Background(), email, comment, subaccount)
+}
+
+// AddContext is like Add but carries ctx through the request
+func (r *Rejects) AddContext(ctx context.Context, email string, comment string, subaccount string) (RejectsAddResponse, error) {
+	var ret RejectsAddResponse
 	data := struct {
 		APIKey     string `json:"key"`
 		Email      string `json:"email"`
 		Comment    string `json:"comment,omitempty"`
 		Subaccount string `json:"subaccount,omitempty"`
 	}{r.m.APIKey, email, comment, subaccount}
-	body, err := r.m.execute("/rejects/add.json", data)
+	body, err := r.m.execute(ctx, "/rejects/add.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/rejects/add.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -33,8 +38,8 @@ func (r *Rejects) Add(email string, comment string, subaccount string) (rejectsA
 	return ret, nil
 }
 
-// rejectsAddResponse contains the address and the result of the operation
-type rejectsAddResponse struct {
+// RejectsAddResponse contains the address and the result of the operation
+type RejectsAddResponse struct {
 	// the email address you provided
 	Email string `json:"email"`
 
@@ -44,19 +49,24 @@ type rejectsAddResponse struct {
 
 // Delete an email rejection. There is no limit to how many rejections you can remove from your blacklist
 // however each deletion has an effect on your reputation
-func (r *Rejects) Delete(email string, subaccount string) (rejectsDeleteResponse, error) {
-	var ret rejectsDeleteResponse
+func (r *Rejects) Delete(email string, subaccount string) (RejectsDeleteResponse, error) {
+	return r.DeleteContext(context.Background(), email, subaccount)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (r *Rejects) DeleteContext(ctx context.Context, email string, subaccount string) (RejectsDeleteResponse, error) {
+	var ret RejectsDeleteResponse
 	data := struct {
 		APIKey     string `json:"key"`
 		Email      string `json:"email"`
 		Subaccount string `json:"subaccount,omitempty"`
 	}{r.m.APIKey, email, subaccount}
-	body, err := r.m.execute("/rejects/delete.json", data)
+	body, err := r.m.execute(ctx, "/rejects/delete.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/rejects/delete.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -64,8 +74,8 @@ func (r *Rejects) Delete(email string, subaccount string) (rejectsDeleteResponse
 	return ret, nil
 }
 
-// rejectsDeleteResponse contains the address and whether the deletion succeeded
-type rejectsDeleteResponse struct {
+// RejectsDeleteResponse contains the address and whether the deletion succeeded
+type RejectsDeleteResponse struct {
 	// the email address that was removed from the blacklist
 	Email string `json:"email"`
 
@@ -77,20 +87,25 @@ type rejectsDeleteResponse struct {
 }
 
 // List retrieves up to 1000 rejection entries
-func (r *Rejects) List(email string, expired bool, subaccount string) ([]rejectsListResponse, error) {
-	var ret []rejectsListResponse
+func (r *Rejects) List(email string, expired bool, subaccount string) ([]RejectsListResponse, error) {
+	return r.ListContext(context.Background(), email, expired, subaccount)
+}
+
+// ListContext is like List but carries ctx through the request
+func (r *Rejects) ListContext(ctx context.Context, email string, expired bool, subaccount string) ([]RejectsListResponse, error) {
+	var ret []RejectsListResponse
 	data := struct {
 		APIKey         string `json:"key"`
 		Email          string `json:"email,omitempty"`
 		IncludeExpired bool   `json:"include_expired,omitempty"`
 		Subaccount     string `json:"subaccount,omitempty`
 	}{r.m.APIKey, email, expired, subaccount}
-	body, err := r.m.execute("/rejects/list.json", data)
+	body, err := r.m.execute(ctx, "/rejects/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/rejects/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -98,8 +113,8 @@ func (r *Rejects) List(email string, expired bool, subaccount string) ([]rejects
 	return ret, nil
 }
 
-// rejectsListResponse contains information for each rejection blacklist entry
-type rejectsListResponse struct {
+// RejectsListResponse contains information for each rejection blacklist entry
+type RejectsListResponse struct {
 	// the email that is blocked
 	Email string `json:"email"`
 
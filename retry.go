@@ -0,0 +1,127 @@
+package mandrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are retried.
+// Transient errors are network errors, non-JSON error responses such as a 502 from
+// a load balancer, 429 responses and Mandrill's GeneralError
+type RetryPolicy struct {
+	// the total number of attempts, including the first. Values below 2 disable retries
+	MaxAttempts int
+
+	// the delay before the first retry. Each further retry doubles the delay
+	InitialBackoff time.Duration
+
+	// the upper bound on the delay between attempts
+	MaxBackoff time.Duration
+
+	// whether to retry endpoints that send email, such as messages/send, after the
+	// request may have reached Mandrill. Enabling this risks sending duplicate emails.
+	// When disabled, these endpoints are only retried if the connection could not be made
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is a conservative policy suitable for most callers
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// WithRetry retries transient failures according to p
+func WithRetry(p RetryPolicy) Option {
+	return func(m *Mandrill) {
+		m.Retry = &p
+	}
+}
+
+// nonIdempotentPaths are endpoints that may send duplicate email if repeated
+var nonIdempotentPaths = map[string]bool{
+	"/messages/send.json":          true,
+	"/messages/send-template.json": true,
+	"/messages/send-raw.json":      true,
+	"/inbound/send-raw.json":       true,
+}
+
+// RetryError is returned when a request still fails after being retried
+type RetryError struct {
+	// the number of attempts made
+	Attempts int
+
+	// the error from the last attempt
+	Err error
+}
+
+func (r *RetryError) Error() string {
+	return fmt.Sprintf("failed after %d attempts: %s", r.Attempts, r.Err)
+}
+
+func (r *RetryError) Unwrap() error {
+	return r.Err
+}
+
+// retryable reports whether a request to path that failed with err and status should be retried
+func (p *RetryPolicy) retryable(path string, status int, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if nonIdempotentPaths[path] && !p.RetryNonIdempotent {
+		var opErr *net.OpError
+		return status == 0 && errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+
+	// no response received
+	if status == 0 {
+		return true
+	}
+
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.Name == "GeneralError"
+	}
+
+	return status >= 500
+}
+
+// backoff returns the delay after the given attempt: exponential growth capped
+// at MaxBackoff, with jitter drawn from the upper half of the interval
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	// without a MaxBackoff, doubling stops before d would overflow
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// sleepContext pauses for d or until ctx is done, in which case ctx.Err() is returned
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+package mandrill
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestRetryTransient(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			http.Error(w, "<html>bad gateway</html>", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`"PONG!"`))
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL), WithRetry(testRetryPolicy))
+	ok, err := m.Users().Ping()
+	if err != nil || !ok {
+		t.Errorf("expected pong. Received: %v, %s", ok, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts. Received: %d", calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL), WithRetry(testRetryPolicy))
+	_, err := m.Users().Ping()
+	var re *RetryError
+	if !errors.As(err, &re) || re.Attempts != 3 {
+		t.Errorf("expected retry error after 3 attempts. Received: %v", err)
+	}
+}
+
+func TestRetrySkipsAPIError(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error","code":-1,"name":"Invalid_Key","message":"Invalid API key"}`))
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL), WithRetry(testRetryPolicy))
+	_, err := m.Users().Ping()
+	if ae, ok := err.(*APIError); !ok || ae.Name != "Invalid_Key" || calls != 1 {
+		t.Errorf("expected single invalid key error. Received: %s after %d calls", err, calls)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	m := NewMandrill("dummy-key", WithBaseURL(ts.URL), WithRetry(testRetryPolicy))
+	msg := &Message{FromEmail: "from@example.com", To: []Recipient{{Email: "to@example.com"}}}
+	if _, err := m.Messages().Send(msg, false, "", nil); err == nil || calls != 1 {
+		t.Errorf("expected send to fail without retry. Received: %v after %d calls", err, calls)
+	}
+
+	// connection refused means the message never reached the server
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	addr := l.Addr().String()
+	l.Close()
+	m = NewMandrill("dummy-key", WithBaseURL("http://"+addr), WithRetry(testRetryPolicy))
+	_, err = m.Messages().Send(msg, false, "", nil)
+	var re *RetryError
+	if !errors.As(err, &re) || re.Attempts != 3 {
+		t.Errorf("expected retry error after 3 attempts. Received: %v", err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for i, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		attempt := i + 1
+		max *= time.Millisecond
+		if d := p.backoff(attempt); d < max/2 || d > max {
+			t.Errorf("attempt %d: expected backoff between %s and %s. Received: %s", attempt, max/2, max, d)
+		}
+	}
+
+	// without a MaxBackoff the delay keeps growing instead of overflowing
+	p = RetryPolicy{InitialBackoff: time.Second}
+	for _, attempt := range []int{35, 80, 1000} {
+		if d := p.backoff(attempt); d < time.Duration(math.MaxInt64/4) {
+			t.Errorf("attempt %d: expected a very long backoff. Received: %s", attempt, d)
+		}
+	}
+}
@@ -1,14 +1,14 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Senders struct {
 	m *Mandrill
 }
 
-type txtRecord struct {
+type TXTRecord struct {
 	// whether this domain's record is valid for use with Mandrill
 	Valid bool `json:"valid"`
 
@@ -23,15 +23,20 @@ type txtRecord struct {
 }
 
 // List senders that have tried to use this account
-func (s *Senders) List() ([]sendersListResponse, error) {
-	var ret []sendersListResponse
+func (s *Senders) List() ([]SendersListResponse, error) {
+	return s.ListContext(context.Background())
+}
+
+// ListContext is like List but carries ctx through the request
+func (s *Senders) ListContext(ctx context.Context) ([]SendersListResponse, error) {
+	var ret []SendersListResponse
 	data := simpleRequest{s.m.APIKey}
-	body, err := s.m.execute("/senders/list.json", data)
+	body, err := s.m.execute(ctx, "/senders/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/senders/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -39,8 +44,8 @@ func (s *Senders) List() ([]sendersListResponse, error) {
 	return ret, nil
 }
 
-// sendersListResponse has data for each sending addresses used by the account
-type sendersListResponse struct {
+// SendersListResponse has data for each sending addresses used by the account
+type SendersListResponse struct {
 	Address      string `json:"address"`
 	CreatedAt    string `json:"created_at"`
 	Sent         int    `json:"sent"`
@@ -56,15 +61,20 @@ type sendersListResponse struct {
 }
 
 // Domains return sender domains that have been added to this account.
-func (s *Senders) Domains() ([]sendersDomain, error) {
-	var ret []sendersDomain
+func (s *Senders) Domains() ([]SendersDomain, error) {
+	return s.DomainsContext(context.Background())
+}
+
+// DomainsContext is like Domains but carries ctx through the request
+func (s *Senders) DomainsContext(ctx context.Context) ([]SendersDomain, error) {
+	var ret []SendersDomain
 	data := simpleRequest{s.m.APIKey}
-	body, err := s.m.execute("/senders/domains.json", data)
+	body, err := s.m.execute(ctx, "/senders/domains.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/senders/domains.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -72,8 +82,8 @@ func (s *Senders) Domains() ([]sendersDomain, error) {
 	return ret, nil
 }
 
-// sendersDomain has data for each sending domain used by the account
-type sendersDomain struct {
+// SendersDomain has data for each sending domain used by the account
+type SendersDomain struct {
 	// the sender domain name
 	Domain    string `json:"domain"`
 	CreatedAt string `json:"created_at"`
@@ -82,10 +92,10 @@ type sendersDomain struct {
 	LastTestedAt string `json:"last_tested_at"`
 
 	// details about the domain's SPF record
-	SPF txtRecord `json:"spf"`
+	SPF TXTRecord `json:"spf"`
 
 	// details about the domain's DKIM record
-	DKIM txtRecord `json:"dkim"`
+	DKIM TXTRecord `json:"dkim"`
 
 	// if the domain has been verified, when it occurred as a UTC string
 	VerifiedAt string `json:"verified_at"`
@@ -98,18 +108,23 @@ type sendersDomain struct {
 
 // AddDomain to your account. Sender domains are added automatically as you send,
 // but you can use this call to add them ahead of time.
-func (s *Senders) AddDomain(domain string) (sendersDomain, error) {
-	var ret sendersDomain
+func (s *Senders) AddDomain(domain string) (SendersDomain, error) {
+	return s.AddDomainContext(context.Background(), domain)
+}
+
+// AddDomainContext is like AddDomain but carries ctx through the request
+func (s *Senders) AddDomainContext(ctx context.Context, domain string) (SendersDomain, error) {
+	var ret SendersDomain
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{s.m.APIKey, domain}
-	body, err := s.m.execute("/senders/add-domain.json", data)
+	body, err := s.m.execute(ctx, "/senders/add-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/senders/add-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -119,18 +134,23 @@ func (s *Senders) AddDomain(domain string) (sendersDomain, error) {
 
 // Checks the SPF and DKIM settings for a domain. If you haven't already added
 // this domain to your account, it will be added automatically.
-func (s *Senders) CheckDomain(domain string) (sendersDomain, error) {
-	var ret sendersDomain
+func (s *Senders) CheckDomain(domain string) (SendersDomain, error) {
+	return s.CheckDomainContext(context.Background(), domain)
+}
+
+// CheckDomainContext is like CheckDomain but carries ctx through the request
+func (s *Senders) CheckDomainContext(ctx context.Context, domain string) (SendersDomain, error) {
+	var ret SendersDomain
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{s.m.APIKey, domain}
-	body, err := s.m.execute("/senders/check-domain.json", data)
+	body, err := s.m.execute(ctx, "/senders/check-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/senders/check-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -143,19 +163,24 @@ func (s *Senders) CheckDomain(domain string) (sendersDomain, error) {
 // has been verified in a Mandrill account, other accounts may not have their
 // messages signed by that domain unless they also verify the domain. This prevents
 //other Mandrill accounts from sending mail signed by your domain.
-func (s *Senders) VerifyDomain(domain string, mailbox string) (sendersVerifyResponse, error) {
-	var ret sendersVerifyResponse
+func (s *Senders) VerifyDomain(domain string, mailbox string) (SendersVerifyResponse, error) {
+	return s.VerifyDomainContext(context.Background(), domain, mailbox)
+}
+
+// VerifyDomainContext is like VerifyDomain but carries ctx through the request
+func (s *Senders) VerifyDomainContext(ctx context.Context, domain string, mailbox string) (SendersVerifyResponse, error) {
+	var ret SendersVerifyResponse
 	data := struct {
 		APIKey  string `json:"key"`
 		Domain  string `json:"domain"`
 		Mailbox string `json:"mailbox"`
 	}{s.m.APIKey, domain, mailbox}
-	body, err := s.m.execute("/senders/verify-domain.json", data)
+	body, err := s.m.execute(ctx, "/senders/verify-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/senders/verify-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -163,25 +188,30 @@ func (s *Senders) VerifyDomain(domain string, mailbox string) (sendersVerifyResp
 	return ret, nil
 }
 
-type sendersVerifyResponse struct {
+type SendersVerifyResponse struct {
 	Status string `json:"status"`
 	Domain string `json:"domain"`
 	Email  string `json:"email"`
 }
 
 // Info returns detailed information about a single sender, including aggregates of recent stats
-func (s *Senders) Info(address string) (sendersInfoResponse, error) {
-	var ret sendersInfoResponse
+func (s *Senders) Info(address string) (SendersInfoResponse, error) {
+	return s.InfoContext(context.Background(), address)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (s *Senders) InfoContext(ctx context.Context, address string) (SendersInfoResponse, error) {
+	var ret SendersInfoResponse
 	data := struct {
 		APIKey  string `json:"key"`
 		Address string `json:"address"`
 	}{s.m.APIKey, address}
-	body, err := s.m.execute("/senders/info.json", data)
+	body, err := s.m.execute(ctx, "/senders/info.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/senders/info.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -189,7 +219,7 @@ func (s *Senders) Info(address string) (sendersInfoResponse, error) {
 	return ret, nil
 }
 
-type sendersInfoResponse struct {
+type SendersInfoResponse struct {
 	Address     string    `json:"address"`
 	CreatedAt   string    `json:"created_at"`
 	Sent        int       `json:"sent"`
@@ -204,18 +234,23 @@ type sendersInfoResponse struct {
 }
 
 // TimeSeries return hourly stats for the last 30 days for a sender
-func (s *Senders) TimeSeries(address string) ([]senderTimeSeries, error) {
-	var ret []senderTimeSeries
+func (s *Senders) TimeSeries(address string) ([]SenderTimeSeries, error) {
+	return s.TimeSeriesContext(context.Background(), address)
+}
+
+// TimeSeriesContext is like TimeSeries but carries ctx through the request
+func (s *Senders) TimeSeriesContext(ctx context.Context, address string) ([]SenderTimeSeries, error) {
+	var ret []SenderTimeSeries
 	data := struct {
 		APIKey  string `json:"key"`
 		Address string `json:"address"`
 	}{s.m.APIKey, address}
-	body, err := s.m.execute("/senders/time-series.json", data)
+	body, err := s.m.execute(ctx, "/senders/time-series.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/senders/time-series.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -223,7 +258,7 @@ func (s *Senders) TimeSeries(address string) ([]senderTimeSeries, error) {
 	return ret, nil
 }
 
-type senderTimeSeries struct {
+type SenderTimeSeries struct {
 	Time         string `json:"time"`
 	Sent         int    `json:"sent"`
 	HardBounces  int    `json:"hard_bounces"`
@@ -0,0 +1,369 @@
+package mandrill
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// SMTPRelayAddr is the address of the Mandrill SMTP relay
+const SMTPRelayAddr = "smtp.mandrillapp.com:587"
+
+// ErrNoStartTLS is returned when the SMTP server does not support STARTTLS
+var ErrNoStartTLS = errors.New("mandrill: smtp server does not support STARTTLS")
+
+// SMTPSender sends messages through the Mandrill SMTP relay. Message options that have no
+// MIME equivalent are sent as X-MC-* headers. The relay does not report message ids or
+// per-recipient status, so every accepted recipient is reported as "queued", or
+// "scheduled" if sendAt is given
+type SMTPSender struct {
+	// Addr is the host:port of the relay, SMTPRelayAddr by default
+	Addr string
+
+	// Username is the account's SMTP username
+	Username string
+
+	// APIKey is used as the SMTP password
+	APIKey string
+
+	// TLSConfig configures STARTTLS, which is always required. If nil the relay's
+	// certificate is verified against its host name
+	TLSConfig *tls.Config
+
+	// Timeout bounds each send, including the dial, when ctx has no deadline.
+	// DefaultTimeout if zero
+	Timeout time.Duration
+}
+
+// NewSMTPSender returns an SMTPSender for the Mandrill SMTP relay
+func NewSMTPSender(username, apiKey string) *SMTPSender {
+	return &SMTPSender{Addr: SMTPRelayAddr, Username: username, APIKey: apiKey}
+}
+
+func (s *SMTPSender) Send(message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	return s.SendContext(context.Background(), message, async, ipPool, sendAt)
+}
+
+// SendContext is like Send but carries ctx through the SMTP conversation.
+// async has no effect, as the relay always queues messages
+func (s *SMTPSender) SendContext(ctx context.Context, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	return s.send(ctx, message, "", ipPool, sendAt)
+}
+
+// SendTemplate sends message using a template. The relay can only fill a single editable
+// region, so templateContent may have at most one entry, whose content replaces the message html
+func (s *SMTPSender) SendTemplate(templateName string, templateContent []TemplateMergeVar, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	return s.SendTemplateContext(context.Background(), templateName, templateContent, message, async, ipPool, sendAt)
+}
+
+// SendTemplateContext is like SendTemplate but carries ctx through the SMTP conversation
+func (s *SMTPSender) SendTemplateContext(ctx context.Context, templateName string, templateContent []TemplateMergeVar, message *Message, async bool, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	if message == nil {
+		return nil, errors.New("mandrill: no message to send")
+	}
+	if len(templateContent) > 1 {
+		return nil, errors.New("mandrill: smtp templates support a single editable region")
+	}
+	template := templateName
+	if len(templateContent) == 1 {
+		msg := *message
+		msg.HTML = fmt.Sprint(templateContent[0].Content)
+		message = &msg
+		template += "|" + templateContent[0].Name
+	}
+	return s.send(ctx, message, template, ipPool, sendAt)
+}
+
+func (s *SMTPSender) send(ctx context.Context, message *Message, template string, ipPool string, sendAt *time.Time) ([]SendResponse, error) {
+	if message == nil {
+		return nil, errors.New("mandrill: no message to send")
+	}
+	if len(message.To) == 0 {
+		return nil, errors.New("mandrill: message has no recipients")
+	}
+	raw, err := SMTPMessage(message, template, ipPool, sendAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deliver(ctx, message, raw); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	status := "queued"
+	if sendAt != nil {
+		status = "scheduled"
+	}
+	ret := make([]SendResponse, len(message.To))
+	for i, r := range message.To {
+		ret[i] = SendResponse{Email: r.Email, Status: status}
+	}
+	return ret, nil
+}
+
+// deliver sends raw to the message's recipients over a new connection
+func (s *SMTPSender) deliver(ctx context.Context, message *Message, raw []byte) error {
+	addr := s.Addr
+	if addr == "" {
+		addr = SMTPRelayAddr
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		timeout := s.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		deadline = time.Now().Add(timeout)
+	}
+	d := net.Dialer{Deadline: deadline}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); !ok {
+		return ErrNoStartTLS
+	}
+	config := s.TLSConfig
+	if config == nil {
+		config = &tls.Config{ServerName: host}
+	}
+	if err := c.StartTLS(config); err != nil {
+		return err
+	}
+	if err := c.Auth(smtp.PlainAuth("", s.Username, s.APIKey, host)); err != nil {
+		return err
+	}
+	if err := c.Mail(message.FromEmail); err != nil {
+		return err
+	}
+	for _, r := range message.To {
+		if err := c.Rcpt(r.Email); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// SMTPMessage renders message as MIME for the SMTP relay, adding X-MC-* headers for the
+// options of message, template, ipPool and sendAt that have no MIME equivalent.
+// template is "name" or "name|region" and may be empty. The X-MC-* headers are written as
+// they are, with JSON values escaped to ASCII, and long values are folded
+func SMTPMessage(message *Message, template string, ipPool string, sendAt *time.Time) ([]byte, error) {
+	var headers strings.Builder
+	written := make(map[string]bool)
+	var err error
+	set := func(k, v string) {
+		if v == "" || err != nil {
+			return
+		}
+		if err = checkHeader(k, v); err != nil {
+			return
+		}
+		headers.WriteString(foldHeader(k, v, false))
+		written[strings.ToLower(k)] = true
+	}
+	setBool := func(k string, v bool) {
+		if v {
+			set(k, "true")
+		}
+	}
+	setJSON := func(k string, v interface{}) {
+		if err != nil {
+			return
+		}
+		var b string
+		if b, err = headerJSON(v); err == nil {
+			headers.WriteString(foldHeader(k, b, true))
+			written[strings.ToLower(k)] = true
+		}
+	}
+
+	var track []string
+	if message.TrackOpens {
+		track = append(track, "opens")
+	}
+	if message.TrackClicks {
+		track = append(track, "clicks")
+	}
+	set("X-MC-Track", strings.Join(track, ","))
+	set("X-MC-Tags", strings.Join(message.Tags, ","))
+	set("X-MC-Subaccount", message.SubAccount)
+	set("X-MC-Template", template)
+	set("X-MC-IpPool", ipPool)
+	if sendAt != nil {
+		set("X-MC-SendAt", ToMandrillTime(*sendAt))
+	}
+	set("X-MC-MergeLanguage", message.MergeLang)
+	set("X-MC-BccAddress", message.BCCAddress)
+	set("X-MC-TrackingDomain", message.TrackingDomain)
+	set("X-MC-SigningDomain", message.SigningDomain)
+	set("X-MC-ReturnPathDomain", message.ReturnPathDomain)
+	set("X-MC-GoogleAnalytics", strings.Join(message.GoogleAnalyticsDomain, ","))
+	set("X-MC-GoogleAnalyticsCampaign", strings.Join(message.GoogleAnalyticsCampaign, ","))
+	setBool("X-MC-Important", message.Important)
+	setBool("X-MC-Autotext", message.AutoText)
+	setBool("X-MC-AutoHtml", message.AutoHTML)
+	setBool("X-MC-InlineCSS", message.InlineCSS)
+	setBool("X-MC-URLStripQS", message.URLStripQueries)
+	setBool("X-MC-PreserveRecipients", message.PreserveRecipients)
+	setBool("X-MC-ViewContentLink", message.ViewContentLink)
+
+	if len(message.GlobalMergeVars) > 0 {
+		vars := make(map[string]interface{})
+		for _, v := range message.GlobalMergeVars {
+			vars[v.Name] = v.Content
+		}
+		setJSON("X-MC-MergeVars", vars)
+	}
+	if len(message.Metadata) > 0 {
+		setJSON("X-MC-Metadata", message.Metadata)
+	}
+
+	// per-recipient values are sent as repeated headers naming the recipient in _rcpt
+	for _, rv := range message.MergeVars {
+		vars := map[string]interface{}{"_rcpt": rv.Recipient}
+		for _, v := range rv.Vars {
+			vars[v.Name] = v.Content
+		}
+		setJSON("X-MC-MergeVars", vars)
+	}
+	for _, rm := range message.RecipientMetadata {
+		values := map[string]string{"_rcpt": rm.Recipient}
+		for k, v := range rm.Values {
+			values[k] = v
+		}
+		setJSON("X-MC-Metadata", values)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// custom headers replaced by the options above are dropped
+	msg := *message
+	msg.Headers = make(map[string]string, len(message.Headers))
+	for k, v := range message.Headers {
+		if !written[strings.ToLower(k)] {
+			msg.Headers[k] = v
+		}
+	}
+	raw, err := msg.ToMIME()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(headers.String()), raw...), nil
+}
+
+// maxHeaderLine is the line length headers are folded to where possible
+const maxHeaderLine = 78
+
+// foldHeader renders the header name: value, folding it into lines of at most maxHeaderLine
+// characters where possible. Plain values are folded at spaces; JSON values, which have
+// none, are folded after commas between tokens, where the space added by folding is allowed
+func foldHeader(name, value string, isJSON bool) string {
+	segs, sep := strings.Split(value, " "), " "
+	if isJSON {
+		segs, sep = jsonSegments(value), ""
+	}
+	var b strings.Builder
+	b.WriteString(name + ":")
+	line := len(name) + 1
+	for i, seg := range segs {
+		s := sep + seg
+		if i == 0 {
+			s = " " + seg
+		} else if line+len(s) > maxHeaderLine {
+			b.WriteString("\r\n")
+			s, line = " "+seg, 0
+		}
+		b.WriteString(s)
+		line += len(s)
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// jsonSegments splits a JSON document after each comma outside a string
+func jsonSegments(s string) []string {
+	var segs []string
+	inString, escaped, start := false, false, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && c == ',':
+			segs = append(segs, s[start:i+1])
+			start = i + 1
+		}
+	}
+	return append(segs, s[start:])
+}
+
+// headerJSON returns v as JSON with non-ASCII characters escaped, so that it can be
+// written in a header as it is
+func headerJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, r := range string(b) {
+		switch {
+		case r < utf8.RuneSelf:
+			sb.WriteRune(r)
+		case r > 0xffff:
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&sb, "\\u%04x\\u%04x", r1, r2)
+		default:
+			fmt.Fprintf(&sb, "\\u%04x", r)
+		}
+	}
+	return sb.String(), nil
+}
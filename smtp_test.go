@@ -0,0 +1,232 @@
+package mandrill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimtsao/mandrill/mandrilltest"
+)
+
+func TestSMTPSender(t *testing.T) {
+	srv := mandrilltest.NewSMTPServer()
+	defer srv.Close()
+	s := &SMTPSender{
+		Addr:      srv.Addr,
+		Username:  "smtp-user",
+		APIKey:    mandrilltest.APIKey,
+		TLSConfig: srv.ClientTLSConfig(),
+	}
+	msg := &Message{
+		FromEmail:   TestFromEmail,
+		Subject:     "Test SMTP",
+		Text:        "Test SMTP",
+		To:          []Recipient{{Email: "accept@test.mandrillapp.com"}, {Email: "bcc@example.com", Type: RecipientBCC}},
+		Tags:        []string{"one", "two"},
+		SubAccount:  "sub",
+		TrackOpens:  true,
+		TrackClicks: true,
+		Metadata:    map[string]string{"user_id": "123"},
+		GlobalMergeVars: []MergeVar{
+			{Name: "COMPANY", Content: "Example"},
+		},
+		MergeVars: []RecipientMergeVar{
+			{Recipient: "accept@test.mandrillapp.com", Vars: []MergeVar{{Name: "NAME", Content: "Jane"}}},
+		},
+	}
+	sendAt := time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	var sender MessageSender = s
+	rr, err := sender.Send(msg, false, "pool", &sendAt)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(rr) != 2 || rr[0].Status != "scheduled" {
+		t.Errorf("expected 2 scheduled responses. Received: %+v", rr)
+	}
+
+	mails := srv.Mails()
+	if len(mails) != 1 {
+		t.Errorf("expected 1 mail. Received: %d", len(mails))
+		return
+	}
+	ml := mails[0]
+	if !ml.TLS || ml.Username != "smtp-user" || ml.From != TestFromEmail || strings.Join(ml.To, ",") != "accept@test.mandrillapp.com,bcc@example.com" {
+		t.Errorf("unexpected envelope. Received: %+v", ml)
+	}
+	m, err := mail.ReadMessage(bytes.NewReader(ml.Data))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := map[string]string{
+		"X-MC-Tags":       "one,two",
+		"X-MC-Subaccount": "sub",
+		"X-MC-Track":      "opens,clicks",
+		"X-MC-IpPool":     "pool",
+		"X-MC-SendAt":     "2030-01-02 03:04:05",
+		"X-MC-Metadata":   `{"user_id":"123"}`,
+	}
+	for k, v := range expected {
+		if m.Header.Get(k) != v {
+			t.Errorf("expected %s: %s. Received: %q", k, v, m.Header.Get(k))
+		}
+	}
+	vars := strings.Join(m.Header["X-Mc-Mergevars"], " ")
+	if !strings.Contains(vars, `{"COMPANY":"Example"}`) || !strings.Contains(vars, `{"NAME":"Jane","_rcpt":"accept@test.mandrillapp.com"}`) {
+		t.Errorf("unexpected merge vars. Received: %q", vars)
+	}
+}
+
+func TestSMTPSenderTemplate(t *testing.T) {
+	srv := mandrilltest.NewSMTPServer()
+	defer srv.Close()
+	s := &SMTPSender{Addr: srv.Addr, APIKey: mandrilltest.APIKey, TLSConfig: srv.ClientTLSConfig()}
+	msg := &Message{FromEmail: TestFromEmail, To: []Recipient{{Email: "accept@test.mandrillapp.com"}}}
+	content := []TemplateMergeVar{{Name: "main", Content: "<p>Main</p>"}}
+	if _, err := s.SendTemplate("welcome", content, msg, false, "", nil); err != nil {
+		t.Error(err)
+		return
+	}
+	mails := srv.Mails()
+	if len(mails) != 1 || !bytes.Contains(mails[0].Data, []byte("X-MC-Template: welcome|main")) || !bytes.Contains(mails[0].Data, []byte("<p>Main</p>")) {
+		t.Errorf("expected template header and content. Received: %+v", mails)
+	}
+}
+
+func TestSMTPSenderErrors(t *testing.T) {
+	srv := mandrilltest.NewSMTPServer()
+	defer srv.Close()
+	msg := &Message{FromEmail: TestFromEmail, To: []Recipient{{Email: "accept@test.mandrillapp.com"}}}
+
+	s := &SMTPSender{Addr: srv.Addr, APIKey: "bad-key", TLSConfig: srv.ClientTLSConfig()}
+	if _, err := s.Send(msg, false, "", nil); err == nil {
+		t.Errorf("expected authentication error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.APIKey = mandrilltest.APIKey
+	if _, err := s.SendContext(ctx, msg, false, "", nil); err != context.Canceled {
+		t.Errorf("expected context.Canceled. Received: %v", err)
+	}
+
+	srv.SetStartTLS(false)
+	if _, err := s.Send(msg, false, "", nil); !errors.Is(err, ErrNoStartTLS) {
+		t.Errorf("expected ErrNoStartTLS. Received: %v", err)
+	}
+	if len(srv.Mails()) != 0 {
+		t.Errorf("expected no mails. Received: %+v", srv.Mails())
+	}
+}
+
+func TestSMTPSenderTimeout(t *testing.T) {
+	// a relay that accepts connections but never replies
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	s := &SMTPSender{Addr: l.Addr().String(), APIKey: mandrilltest.APIKey, Timeout: 50 * time.Millisecond}
+	msg := &Message{FromEmail: TestFromEmail, To: []Recipient{{Email: "accept@test.mandrillapp.com"}}}
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Send(msg, false, "", nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		var ne net.Error
+		if !errors.As(err, &ne) || !ne.Timeout() {
+			t.Errorf("expected timeout error. Received: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected send to time out")
+	}
+}
+
+func TestSMTPMessageHeaders(t *testing.T) {
+	items := make([]map[string]interface{}, 200)
+	for i := range items {
+		items[i] = map[string]interface{}{"name": "Crème brûlée", "qty": i}
+	}
+	msg := &Message{
+		FromEmail:       TestFromEmail,
+		Text:            "Test",
+		To:              []Recipient{{Email: "jane@example.com"}},
+		GlobalMergeVars: []MergeVar{{Name: "ITEMS", Content: items}, {Name: "GREETING", Content: "Grüß dich 👋"}},
+		Metadata:        map[string]string{"city": "Zürich"},
+		Headers:         map[string]string{"X-MC-Metadata": "replaced"},
+	}
+	raw, err := SMTPMessage(msg, "", "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 78 {
+			t.Errorf("expected header lines to be folded. Received %d characters: %s", len(line), line)
+			break
+		}
+		if line == "" {
+			break
+		}
+	}
+	if bytes.Contains(raw, []byte("=?utf-8?")) {
+		t.Errorf("expected X-MC headers not to be encoded words")
+	}
+
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var vars struct {
+		Items    []map[string]interface{} `json:"ITEMS"`
+		Greeting string                   `json:"GREETING"`
+	}
+	if err := json.Unmarshal([]byte(m.Header.Get("X-MC-MergeVars")), &vars); err != nil {
+		t.Errorf("expected merge vars JSON. Received: %v", err)
+		return
+	}
+	if len(vars.Items) != 200 || vars.Items[199]["name"] != "Crème brûlée" || vars.Greeting != "Grüß dich 👋" {
+		t.Errorf("unexpected merge vars. Received: %+v", vars)
+	}
+	if md := m.Header["X-Mc-Metadata"]; len(md) != 1 || md[0] != `{"city":"Z\u00fcrich"}` {
+		t.Errorf("expected one escaped metadata header. Received: %q", md)
+	}
+
+	msg.GlobalMergeVars = nil
+	msg.MergeVars = []RecipientMergeVar{{Recipient: "jane@example.com", Vars: []MergeVar{{Name: "NAME", Content: "Jane"}}}}
+	if raw, err = SMTPMessage(msg, "", "", nil); err != nil {
+		t.Error(err)
+		return
+	}
+	m, _ = mail.ReadMessage(bytes.NewReader(raw))
+	if vars := m.Header["X-Mc-Mergevars"]; len(vars) != 1 || vars[0] != `{"NAME":"Jane","_rcpt":"jane@example.com"}` {
+		t.Errorf("expected only the recipient merge vars header. Received: %q", vars)
+	}
+
+	msg.SubAccount = "sub\r\nBcc: victim@example.com"
+	if _, err := SMTPMessage(msg, "", "", nil); err == nil {
+		t.Error("expected error for header value with a line break")
+	}
+}
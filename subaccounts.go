@@ -1,14 +1,14 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Subaccounts struct {
 	m *Mandrill
 }
 
-type subaccountsResponse struct {
+type SubaccountsResponse struct {
 	// a unique indentifier for the subaccount
 	Id string `json:"id"`
 
@@ -45,18 +45,23 @@ type subaccountsResponse struct {
 
 // List subaccounts defined for the account, optionally filtered by a prefix
 // returns up to 1000 subaccounts
-func (s *Subaccounts) List(q string) ([]subaccountsResponse, error) {
-	var ret []subaccountsResponse
+func (s *Subaccounts) List(q string) ([]SubaccountsResponse, error) {
+	return s.ListContext(context.Background(), q)
+}
+
+// ListContext is like List but carries ctx through the request
+func (s *Subaccounts) ListContext(ctx context.Context, q string) ([]SubaccountsResponse, error) {
+	var ret []SubaccountsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Q      string `json:"q,omitempty"`
 	}{s.m.APIKey, q}
-	body, err := s.m.execute("/subaccounts/list.json", data)
+	body, err := s.m.execute(ctx, "/subaccounts/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/subaccounts/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -65,8 +70,13 @@ func (s *Subaccounts) List(q string) ([]subaccountsResponse, error) {
 }
 
 // Add a new subaccount. Id max length 255. Name max length 1024
-func (s *Subaccounts) Add(id string, name string, notes string, quota int) (subaccountsResponse, error) {
-	var ret subaccountsResponse
+func (s *Subaccounts) Add(id string, name string, notes string, quota int) (SubaccountsResponse, error) {
+	return s.AddContext(context.Background(), id, name, notes, quota)
+}
+
+// AddContext is like Add but carries ctx through the request
+func (s *Subaccounts) AddContext(ctx context.Context, id string, name string, notes string, quota int) (SubaccountsResponse, error) {
+	var ret SubaccountsResponse
 	data := struct {
 		APIKey      string `json:"key"`
 		Id          string `json:"id"`
@@ -74,12 +84,12 @@ func (s *Subaccounts) Add(id string, name string, notes string, quota int) (suba
 		Notes       string `json:"notes,omitempty"`
 		CustomQuota int    `json:"custom_quota,omitempty"`
 	}{s.m.APIKey, id, name, notes, quota}
-	body, err := s.m.execute("/subaccounts/add.json", data)
+	body, err := s.m.execute(ctx, "/subaccounts/add.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/subaccounts/add.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -87,18 +97,23 @@ func (s *Subaccounts) Add(id string, name string, notes string, quota int) (suba
 	return ret, nil
 }
 
-func (s *Subaccounts) Info(id string) (subaccountsInfoResponse, error) {
-	var ret subaccountsInfoResponse
+func (s *Subaccounts) Info(id string) (SubaccountsInfoResponse, error) {
+	return s.InfoContext(context.Background(), id)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (s *Subaccounts) InfoContext(ctx context.Context, id string) (SubaccountsInfoResponse, error) {
+	var ret SubaccountsInfoResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     string `json:"id"`
 	}{s.m.APIKey, id}
-	body, err := s.m.execute("/subaccounts/info.json", data)
+	body, err := s.m.execute(ctx, "/subaccounts/info.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/subaccounts/info.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -106,7 +121,7 @@ func (s *Subaccounts) Info(id string) (subaccountsInfoResponse, error) {
 	return ret, nil
 }
 
-type subaccountsInfoResponse struct {
+type SubaccountsInfoResponse struct {
 	Id          string `json:"id"`
 	Name        string `json:"name"`
 	Notes       string `json:"notes"`
@@ -134,8 +149,13 @@ type subaccountsInfoResponse struct {
 	} `json:"last_30_days"`
 }
 
-func (s *Subaccounts) Update(id string, name string, notes string, quota int) (subaccountsResponse, error) {
-	var ret subaccountsResponse
+func (s *Subaccounts) Update(id string, name string, notes string, quota int) (SubaccountsResponse, error) {
+	return s.UpdateContext(context.Background(), id, name, notes, quota)
+}
+
+// UpdateContext is like Update but carries ctx through the request
+func (s *Subaccounts) UpdateContext(ctx context.Context, id string, name string, notes string, quota int) (SubaccountsResponse, error) {
+	var ret SubaccountsResponse
 	data := struct {
 		APIKey      string `json:"key"`
 		Id          string `json:"id"`
@@ -143,12 +163,12 @@ func (s *Subaccounts) Update(id string, name string, notes string, quota int) (s
 		Notes       string `json:"notes,omitempty"`
 		CustomQuota int    `json:"custom_quota,omitempty"`
 	}{s.m.APIKey, id, name, notes, quota}
-	body, err := s.m.execute("/subaccounts/update.json", data)
+	body, err := s.m.execute(ctx, "/subaccounts/update.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/subaccounts/update.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -156,18 +176,23 @@ func (s *Subaccounts) Update(id string, name string, notes string, quota int) (s
 	return ret, nil
 }
 
-func (s *Subaccounts) Delete(id string) (subaccountsResponse, error) {
-	var ret subaccountsResponse
+func (s *Subaccounts) Delete(id string) (SubaccountsResponse, error) {
+	return s.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (s *Subaccounts) DeleteContext(ctx context.Context, id string) (SubaccountsResponse, error) {
+	var ret SubaccountsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     string `json:"id"`
 	}{s.m.APIKey, id}
-	body, err := s.m.execute("/subaccounts/delete.json", data)
+	body, err := s.m.execute(ctx, "/subaccounts/delete.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/subaccounts/delete.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -177,18 +202,23 @@ func (s *Subaccounts) Delete(id string) (subaccountsResponse, error) {
 
 // Pause a subaccount's sending. Any future emails delivered to this subaccount will
 // be queued for a maximum of 3 days until the subaccount is resumed
-func (s *Subaccounts) Pause(id string) (subaccountsResponse, error) {
-	var ret subaccountsResponse
+func (s *Subaccounts) Pause(id string) (SubaccountsResponse, error) {
+	return s.PauseContext(context.Background(), id)
+}
+
+// PauseContext is like Pause but carries ctx through the request
+func (s *Subaccounts) PauseContext(ctx context.Context, id string) (SubaccountsResponse, error) {
+	var ret SubaccountsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     string `json:"id"`
 	}{s.m.APIKey, id}
-	body, err := s.m.execute("/subaccounts/pause.json", data)
+	body, err := s.m.execute(ctx, "/subaccounts/pause.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/subaccounts/pause.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -196,18 +226,23 @@ func (s *Subaccounts) Pause(id string) (subaccountsResponse, error) {
 	return ret, nil
 }
 
-func (s *Subaccounts) Resume(id string) (subaccountsResponse, error) {
-	var ret subaccountsResponse
+func (s *Subaccounts) Resume(id string) (SubaccountsResponse, error) {
+	return s.ResumeContext(context.Background(), id)
+}
+
+// ResumeContext is like Resume but carries ctx through the request
+func (s *Subaccounts) ResumeContext(ctx context.Context, id string) (SubaccountsResponse, error) {
+	var ret SubaccountsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     string `json:"id"`
 	}{s.m.APIKey, id}
-	body, err := s.m.execute("/subaccounts/resume.json", data)
+	body, err := s.m.execute(ctx, "/subaccounts/resume.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/subaccounts/resume.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -1,7 +1,7 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Tags struct {
@@ -9,21 +9,26 @@ type Tags struct {
 }
 
 // List returns all of the user-defined tag information
-func (t *Tags) List() ([]tagInfo, error) {
-	var ret []tagInfo
-	body, err := t.m.execute("/tags/list.json", simpleRequest{t.m.APIKey})
+func (t *Tags) List() ([]TagInfo, error) {
+	return t.ListContext(context.Background())
+}
+
+// ListContext is like List but carries ctx through the request
+func (t *Tags) ListContext(ctx context.Context) ([]TagInfo, error) {
+	var ret []TagInfo
+	body, err := t.m.execute(ctx, "/tags/list.json", simpleRequest{t.m.APIKey})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/tags/list.json", body, &ret); err != nil {
 		return nil, err
 	}
 
 	return ret, nil
 }
 
-type tagInfo struct {
+type TagInfo struct {
 	// the actual tag as a string
 	Tag string `json:"tag"`
 
@@ -63,9 +68,14 @@ type tagInfo struct {
 
 // Delete permanently removes a tag, its stats and from any messages that have been sent
 // There is no way to undo this operation, so use it carefully.
-func (t *Tags) Delete(tag string) (tagInfo, error) {
-	var ret tagInfo
-	body, err := t.m.execute("/tags/delete.json", struct {
+func (t *Tags) Delete(tag string) (TagInfo, error) {
+	return t.DeleteContext(context.Background(), tag)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (t *Tags) DeleteContext(ctx context.Context, tag string) (TagInfo, error) {
+	var ret TagInfo
+	body, err := t.m.execute(ctx, "/tags/delete.json", struct {
 		APIKey string `json:"key"`
 		Tag    string `json:"tag"`
 	}{t.m.APIKey, tag})
@@ -73,7 +83,7 @@ func (t *Tags) Delete(tag string) (tagInfo, error) {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/tags/delete.json", body, &ret); err != nil {
 		return ret, err
 	}
 
@@ -81,9 +91,14 @@ func (t *Tags) Delete(tag string) (tagInfo, error) {
 }
 
 // Info returns more detailed information about a single tag, including aggregates of recent stats
-func (t *Tags) Info(tag string) (tagStats, error) {
-	var ret tagStats
-	body, err := t.m.execute("/tags/info.json", struct {
+func (t *Tags) Info(tag string) (TagStats, error) {
+	return t.InfoContext(context.Background(), tag)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (t *Tags) InfoContext(ctx context.Context, tag string) (TagStats, error) {
+	var ret TagStats
+	body, err := t.m.execute(ctx, "/tags/info.json", struct {
 		APIKey string `json:"key"`
 		Tag    string `json:"tag"`
 	}{t.m.APIKey, tag})
@@ -91,17 +106,17 @@ func (t *Tags) Info(tag string) (tagStats, error) {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/tags/info.json", body, &ret); err != nil {
 		return ret, err
 	}
 
 	return ret, nil
 }
 
-// tagStats is very similar to tagInfo but we replicate a lot of the field
+// TagStats is very similar to TagInfo but we replicate a lot of the field
 // for clarity, that way every field that is accessible is known to have a
 // value (i.e. no nil pointers or empty fields)
-type tagStats struct {
+type TagStats struct {
 	Tag          string  `json:"tag"`
 	Reputation   int     `json:"reputation"`
 	Sent         int     `json:"sent"`
@@ -114,18 +129,18 @@ type tagStats struct {
 	Clicks       int     `json:"clicks"`
 	UniqueOpens  int     `json:"unique_opens"`
 	UniqueClicks int     `json:"unique_clicks"`
-	Stats        tagStat `json:"stat"`
+	Stats        TagStat `json:"stat"`
 }
 
-type tagStat struct {
-	Today      tagStatDetail `json:"today"`
-	Last7Days  tagStatDetail `json:"last_7_days"`
-	Last30Days tagStatDetail `json:"last_30_days"`
-	Last60Days tagStatDetail `json:"last_60_days"`
-	Last90Days tagStatDetail `json:"last_90_days"`
+type TagStat struct {
+	Today      TagStatDetail `json:"today"`
+	Last7Days  TagStatDetail `json:"last_7_days"`
+	Last30Days TagStatDetail `json:"last_30_days"`
+	Last60Days TagStatDetail `json:"last_60_days"`
+	Last90Days TagStatDetail `json:"last_90_days"`
 }
 
-type tagStatDetail struct {
+type TagStatDetail struct {
 	Reputation   int `json:"reputation"`
 	Sent         int `json:"sent"`
 	HardBounces  int `json:"hard_bounces"`
@@ -140,9 +155,14 @@ type tagStatDetail struct {
 }
 
 // TimeSeries returns hourly stats for the last 30 days for a tag
-func (t *Tags) TimeSeries(tag string) ([]tagTimeSeries, error) {
-	var ret []tagTimeSeries
-	body, err := t.m.execute("/tags/time-series.json", struct {
+func (t *Tags) TimeSeries(tag string) ([]TagTimeSeries, error) {
+	return t.TimeSeriesContext(context.Background(), tag)
+}
+
+// TimeSeriesContext is like TimeSeries but carries ctx through the request
+func (t *Tags) TimeSeriesContext(ctx context.Context, tag string) ([]TagTimeSeries, error) {
+	var ret []TagTimeSeries
+	body, err := t.m.execute(ctx, "/tags/time-series.json", struct {
 		APIKey string `json:"key"`
 		Tag    string `json:"tag"`
 	}{t.m.APIKey, tag})
@@ -150,14 +170,14 @@ func (t *Tags) TimeSeries(tag string) ([]tagTimeSeries, error) {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/tags/time-series.json", body, &ret); err != nil {
 		return ret, err
 	}
 
 	return ret, nil
 }
 
-type tagTimeSeries struct {
+type TagTimeSeries struct {
 	Time         string `json:"time"`
 	Sent         int    `json:"sent"`
 	HardBounces  int    `json:"hard_bounces"`
@@ -172,14 +192,19 @@ type tagTimeSeries struct {
 }
 
 // AllTimeSeries returns hourly stats for the last 30 days for all tags
-func (t *Tags) AllTimeSeries() ([]tagTimeSeries, error) {
-	var ret []tagTimeSeries
-	body, err := t.m.execute("/tags/all-time-series.json", simpleRequest{t.m.APIKey})
+func (t *Tags) AllTimeSeries() ([]TagTimeSeries, error) {
+	return t.AllTimeSeriesContext(context.Background())
+}
+
+// AllTimeSeriesContext is like AllTimeSeries but carries ctx through the request
+func (t *Tags) AllTimeSeriesContext(ctx context.Context) ([]TagTimeSeries, error) {
+	var ret []TagTimeSeries
+	body, err := t.m.execute(ctx, "/tags/all-time-series.json", simpleRequest{t.m.APIKey})
 	if err != nil {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/tags/all-time-series.json", body, &ret); err != nil {
 		return ret, err
 	}
 
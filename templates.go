@@ -1,7 +1,7 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Templates struct {
@@ -19,19 +19,24 @@ type Template struct {
 	Labels    []string `json:"labels,omitempty"`
 }
 
-func (t *Templates) Add(template *Template) (templateResponse, error) {
-	var ret templateResponse
+func (t *Templates) Add(template *Template) (TemplateResponse, error) {
+	return t.AddContext(context.Background(), template)
+}
+
+// AddContext is like Add but carries ctx through the request
+func (t *Templates) AddContext(ctx context.Context, template *Template) (TemplateResponse, error) {
+	var ret TemplateResponse
 	type fakeTemplate Template
 	data := struct {
 		APIKey string `json:"key"`
 		fakeTemplate
 	}{t.m.APIKey, fakeTemplate(*template)}
-	body, err := t.m.execute("/templates/add.json", data)
+	body, err := t.m.execute(ctx, "/templates/add.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/add.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -39,7 +44,7 @@ func (t *Templates) Add(template *Template) (templateResponse, error) {
 	return ret, nil
 }
 
-type templateResponse struct {
+type TemplateResponse struct {
 	// the immutable unique code name of the template
 	Slug string `json:"slug"`
 
@@ -97,18 +102,23 @@ type templateResponse struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
-func (t *Templates) Info(name string) (templateResponse, error) {
-	var ret templateResponse
+func (t *Templates) Info(name string) (TemplateResponse, error) {
+	return t.InfoContext(context.Background(), name)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (t *Templates) InfoContext(ctx context.Context, name string) (TemplateResponse, error) {
+	var ret TemplateResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Name   string `json:"name"`
 	}{t.m.APIKey, name}
-	body, err := t.m.execute("/templates/info.json", data)
+	body, err := t.m.execute(ctx, "/templates/info.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/info.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -116,19 +126,24 @@ func (t *Templates) Info(name string) (templateResponse, error) {
 	return ret, nil
 }
 
-func (t *Templates) Update(template *Template) (templateResponse, error) {
-	var ret templateResponse
+func (t *Templates) Update(template *Template) (TemplateResponse, error) {
+	return t.UpdateContext(context.Background(), template)
+}
+
+// UpdateContext is like Update but carries ctx through the request
+func (t *Templates) UpdateContext(ctx context.Context, template *Template) (TemplateResponse, error) {
+	var ret TemplateResponse
 	type fakeTemplate Template
 	data := struct {
 		APIKey string `json:"key"`
 		fakeTemplate
 	}{t.m.APIKey, fakeTemplate(*template)}
-	body, err := t.m.execute("/templates/update.json", data)
+	body, err := t.m.execute(ctx, "/templates/update.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/update.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -136,18 +151,23 @@ func (t *Templates) Update(template *Template) (templateResponse, error) {
 	return ret, nil
 }
 
-func (t *Templates) Publish(name string) (templateResponse, error) {
-	var ret templateResponse
+func (t *Templates) Publish(name string) (TemplateResponse, error) {
+	return t.PublishContext(context.Background(), name)
+}
+
+// PublishContext is like Publish but carries ctx through the request
+func (t *Templates) PublishContext(ctx context.Context, name string) (TemplateResponse, error) {
+	var ret TemplateResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Name   string `json:"name"`
 	}{t.m.APIKey, name}
-	body, err := t.m.execute("/templates/publish.json", data)
+	body, err := t.m.execute(ctx, "/templates/publish.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/publish.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -155,18 +175,23 @@ func (t *Templates) Publish(name string) (templateResponse, error) {
 	return ret, nil
 }
 
-func (t *Templates) Delete(name string) (templateResponse, error) {
-	var ret templateResponse
+func (t *Templates) Delete(name string) (TemplateResponse, error) {
+	return t.DeleteContext(context.Background(), name)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (t *Templates) DeleteContext(ctx context.Context, name string) (TemplateResponse, error) {
+	var ret TemplateResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Name   string `json:"name"`
 	}{t.m.APIKey, name}
-	body, err := t.m.execute("/templates/delete.json", data)
+	body, err := t.m.execute(ctx, "/templates/delete.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/delete.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -174,18 +199,23 @@ func (t *Templates) Delete(name string) (templateResponse, error) {
 	return ret, nil
 }
 
-func (t *Templates) List(label string) ([]templateResponse, error) {
-	var ret []templateResponse
+func (t *Templates) List(label string) ([]TemplateResponse, error) {
+	return t.ListContext(context.Background(), label)
+}
+
+// ListContext is like List but carries ctx through the request
+func (t *Templates) ListContext(ctx context.Context, label string) ([]TemplateResponse, error) {
+	var ret []TemplateResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Label  string `json:"label"`
 	}{t.m.APIKey, label}
-	body, err := t.m.execute("/templates/list.json", data)
+	body, err := t.m.execute(ctx, "/templates/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -193,18 +223,23 @@ func (t *Templates) List(label string) ([]templateResponse, error) {
 	return ret, nil
 }
 
-func (t *Templates) TimeSeries(name string) ([]templatesTimeSeries, error) {
-	var ret []templatesTimeSeries
+func (t *Templates) TimeSeries(name string) ([]TemplatesTimeSeries, error) {
+	return t.TimeSeriesContext(context.Background(), name)
+}
+
+// TimeSeriesContext is like TimeSeries but carries ctx through the request
+func (t *Templates) TimeSeriesContext(ctx context.Context, name string) ([]TemplatesTimeSeries, error) {
+	var ret []TemplatesTimeSeries
 	data := struct {
 		APIKey string `json:"key"`
 		Name   string `json:"name"`
 	}{t.m.APIKey, name}
-	body, err := t.m.execute("/templates/time-series.json", data)
+	body, err := t.m.execute(ctx, "/templates/time-series.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/time-series.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -212,7 +247,7 @@ func (t *Templates) TimeSeries(name string) ([]templatesTimeSeries, error) {
 	return ret, nil
 }
 
-type templatesTimeSeries struct {
+type TemplatesTimeSeries struct {
 	Time         string `json:"time"`
 	Sent         int    `json:"sent"`
 	HardBounces  int    `json:"hard_bounces"`
@@ -237,6 +272,11 @@ type TemplatesRenderRequest struct {
 }
 
 func (t *Templates) Render(r *TemplatesRenderRequest) (string, error) {
+	return t.RenderContext(context.Background(), r)
+}
+
+// RenderContext is like Render but carries ctx through the request
+func (t *Templates) RenderContext(ctx context.Context, r *TemplatesRenderRequest) (string, error) {
 	var ret struct {
 		HTML string `json:"html"`
 	}
@@ -245,12 +285,12 @@ func (t *Templates) Render(r *TemplatesRenderRequest) (string, error) {
 		APIKey string `json:"key"`
 		fakeRenderRequest
 	}{t.m.APIKey, fakeRenderRequest(*r)}
-	body, err := t.m.execute("/templates/render.json", data)
+	body, err := t.m.execute(ctx, "/templates/render.json", data)
 	if err != nil {
 		return "", err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/templates/render.json", body, &ret)
 	if err != nil {
 		return "", err
 	}
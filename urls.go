@@ -1,7 +1,7 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type URLs struct {
@@ -9,14 +9,19 @@ type URLs struct {
 }
 
 func (u *URLs) List() ([]URLsResponse, error) {
+	return u.ListContext(context.Background())
+}
+
+// ListContext is like List but carries ctx through the request
+func (u *URLs) ListContext(ctx context.Context) ([]URLsResponse, error) {
 	var ret []URLsResponse
 	data := simpleRequest{u.m.APIKey}
-	body, err := u.m.execute("/urls/list.json", data)
+	body, err := u.m.execute(ctx, "/urls/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/urls/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -39,17 +44,22 @@ type URLsResponse struct {
 }
 
 func (u *URLs) Search(query string) ([]URLsResponse, error) {
+	return u.SearchContext(context.Background(), query)
+}
+
+// SearchContext is like Search but carries ctx through the request
+func (u *URLs) SearchContext(ctx context.Context, query string) ([]URLsResponse, error) {
 	var ret []URLsResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Query  string `json:"q"`
 	}{u.m.APIKey, query}
-	body, err := u.m.execute("/urls/search.json", data)
+	body, err := u.m.execute(ctx, "/urls/search.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/urls/search.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -58,17 +68,22 @@ func (u *URLs) Search(query string) ([]URLsResponse, error) {
 }
 
 func (u *URLs) TimeSeries(url string) ([]URLsTimeSeriesResponse, error) {
+	return u.TimeSeriesContext(context.Background(), url)
+}
+
+// TimeSeriesContext is like TimeSeries but carries ctx through the request
+func (u *URLs) TimeSeriesContext(ctx context.Context, url string) ([]URLsTimeSeriesResponse, error) {
 	var ret []URLsTimeSeriesResponse
 	data := struct {
 		APIKey string `json:"key"`
 		URL    string `json:"url"`
 	}{u.m.APIKey, url}
-	body, err := u.m.execute("/urls/time-series.json", data)
+	body, err := u.m.execute(ctx, "/urls/time-series.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/urls/time-series.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -84,14 +99,19 @@ type URLsTimeSeriesResponse struct {
 }
 
 func (u *URLs) TrackingDomains() ([]URLsTrackingDomainResponse, error) {
+	return u.TrackingDomainsContext(context.Background())
+}
+
+// TrackingDomainsContext is like TrackingDomains but carries ctx through the request
+func (u *URLs) TrackingDomainsContext(ctx context.Context) ([]URLsTrackingDomainResponse, error) {
 	var ret []URLsTrackingDomainResponse
 	data := simpleRequest{u.m.APIKey}
-	body, err := u.m.execute("/urls/tracking-domains.json", data)
+	body, err := u.m.execute(ctx, "/urls/tracking-domains.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/urls/tracking-domains.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -112,17 +132,22 @@ type URLsTrackingDomainResponse struct {
 }
 
 func (u *URLs) AddTrackingDomain(domain string) (URLsTrackingDomainResponse, error) {
+	return u.AddTrackingDomainContext(context.Background(), domain)
+}
+
+// AddTrackingDomainContext is like AddTrackingDomain but carries ctx through the request
+func (u *URLs) AddTrackingDomainContext(ctx context.Context, domain string) (URLsTrackingDomainResponse, error) {
 	var ret URLsTrackingDomainResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{u.m.APIKey, domain}
-	body, err := u.m.execute("/urls/add-tracking-domain.json", data)
+	body, err := u.m.execute(ctx, "/urls/add-tracking-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/urls/add-tracking-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -131,17 +156,22 @@ func (u *URLs) AddTrackingDomain(domain string) (URLsTrackingDomainResponse, err
 }
 
 func (u *URLs) CheckTrackingDomain(domain string) (URLsTrackingDomainResponse, error) {
+	return u.CheckTrackingDomainContext(context.Background(), domain)
+}
+
+// CheckTrackingDomainContext is like CheckTrackingDomain but carries ctx through the request
+func (u *URLs) CheckTrackingDomainContext(ctx context.Context, domain string) (URLsTrackingDomainResponse, error) {
 	var ret URLsTrackingDomainResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Domain string `json:"domain"`
 	}{u.m.APIKey, domain}
-	body, err := u.m.execute("/urls/check-tracking-domain.json", data)
+	body, err := u.m.execute(ctx, "/urls/check-tracking-domain.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/urls/check-tracking-domain.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -1,7 +1,7 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Users struct {
@@ -9,13 +9,18 @@ type Users struct {
 }
 
 func (u *Users) Info() (InfoResponse, error) {
+	return u.InfoContext(context.Background())
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (u *Users) InfoContext(ctx context.Context) (InfoResponse, error) {
 	var ret InfoResponse
-	body, err := u.m.execute("/users/info.json", simpleRequest{u.m.APIKey})
+	body, err := u.m.execute(ctx, "/users/info.json", simpleRequest{u.m.APIKey})
 	if err != nil {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/users/info.json", body, &ret); err != nil {
 		return ret, err
 	}
 
@@ -91,19 +96,29 @@ type UserStat struct {
 
 // Ping checks valid connection to server with given API key
 func (u *Users) Ping() (bool, error) {
-	resp, err := u.m.execute("/users/ping.json", simpleRequest{u.m.APIKey})
+	return u.PingContext(context.Background())
+}
+
+// PingContext is like Ping but carries ctx through the request
+func (u *Users) PingContext(ctx context.Context) (bool, error) {
+	resp, err := u.m.execute(ctx, "/users/ping.json", simpleRequest{u.m.APIKey})
 	return string(resp) == `"PONG!"`, err
 }
 
 // Return the senders that have tried to use this account, both verified and unverified
 func (u *Users) Senders() ([]Sender, error) {
-	body, err := u.m.execute("/users/senders.json", simpleRequest{u.m.APIKey})
+	return u.SendersContext(context.Background())
+}
+
+// SendersContext is like Senders but carries ctx through the request
+func (u *Users) SendersContext(ctx context.Context) ([]Sender, error) {
+	body, err := u.m.execute(ctx, "/users/senders.json", simpleRequest{u.m.APIKey})
 	if err != nil {
 		return nil, err
 	}
 
 	var ret []Sender
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/users/senders.json", body, &ret); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,105 @@
+package mandrill
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxInlineCSSSize is the largest html document Mandrill will inline CSS for
+const maxInlineCSSSize = 256 * 1024
+
+// maxTagLength is the longest tag Mandrill accepts
+const maxTagLength = 50
+
+// mergeVarName matches valid merge var names
+var mergeVarName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_]*$`)
+
+// Validate checks msg against the rules documented for its fields, returning a *MessageError
+// listing every problem found. The sender is not required, as a template may supply it
+func (msg *Message) Validate() error {
+	var errs []error
+	errorf := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	for _, r := range msg.To {
+		switch r.Type {
+		case "", RecipientTo, RecipientCC, RecipientBCC:
+		default:
+			errorf("to: %q has type %q, not %q, %q or %q", r.Email, r.Type, RecipientTo, RecipientCC, RecipientBCC)
+		}
+	}
+
+	for _, tag := range msg.Tags {
+		if len(tag) > maxTagLength {
+			errorf("tags: %q is longer than %d characters", tag, maxTagLength)
+		}
+		if strings.HasPrefix(tag, "_") {
+			errorf("tags: %q starts with an underscore", tag)
+		}
+	}
+
+	switch msg.MergeLang {
+	case "", MergeLanguageMailchimp, MergeLanguageHandlebars:
+	default:
+		errorf("merge language: %q is not %q or %q", msg.MergeLang, MergeLanguageMailchimp, MergeLanguageHandlebars)
+	}
+	for _, v := range msg.GlobalMergeVars {
+		if err := checkMergeVarName(v.Name); err != nil {
+			errorf("global merge vars: %s", err)
+		}
+	}
+	for _, rv := range msg.MergeVars {
+		if !msg.hasRecipient(rv.Recipient) {
+			errorf("merge vars: %q is not a recipient", rv.Recipient)
+		}
+		for _, v := range rv.Vars {
+			if err := checkMergeVarName(v.Name); err != nil {
+				errorf("merge vars for %q: %s", rv.Recipient, err)
+			}
+		}
+	}
+	for _, rm := range msg.RecipientMetadata {
+		if !msg.hasRecipient(rm.Recipient) {
+			errorf("recipient metadata: %q is not a recipient", rm.Recipient)
+		}
+	}
+
+	for _, img := range msg.Images {
+		if !strings.HasPrefix(img.Type, "image/") {
+			errorf("images: %q has type %q, which does not start with \"image/\"", img.Name, img.Type)
+		}
+	}
+
+	if msg.InlineCSS && len(msg.HTML) >= maxInlineCSSSize {
+		errorf("inline css: html is %d bytes, CSS is only inlined for html under %d bytes", len(msg.HTML), maxInlineCSSSize)
+	}
+
+	if len(errs) > 0 {
+		return &MessageError{errs}
+	}
+	return nil
+}
+
+func (msg *Message) hasRecipient(email string) bool {
+	for _, r := range msg.To {
+		if strings.EqualFold(r.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMergeVarName returns an error if name is not a valid merge var name
+func checkMergeVarName(name string) error {
+	switch {
+	case strings.HasPrefix(name, "_"):
+		return fmt.Errorf("%q starts with an underscore", name)
+	case strings.Contains(name, ":"):
+		return fmt.Errorf("%q contains a colon", name)
+	case !mergeVarName.MatchString(name):
+		return fmt.Errorf("%q is not made of letters, numbers and underscores", name)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package mandrill
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMessageValidate(t *testing.T) {
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com", Type: "to"}},
+		Tags:      []string{"valid-tag"},
+		GlobalMergeVars: []MergeVar{
+			{Name: "COMPANY_1", Content: "Example"},
+		},
+		MergeVars: []RecipientMergeVar{
+			{Recipient: "accept@test.mandrillapp.com", Vars: []MergeVar{{Name: "NAME", Content: "Jane"}}},
+		},
+		Images: []*Image{{Name: "logo", Type: "image/png"}},
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("expected valid message. Received: %v", err)
+	}
+}
+
+func TestMessageValidateErrors(t *testing.T) {
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		HTML:      strings.Repeat("x", maxInlineCSSSize),
+		InlineCSS: true,
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com", Type: "reply-to"}},
+		Tags:      []string{"_internal", strings.Repeat("t", 51)},
+		MergeLang: "liquid",
+		GlobalMergeVars: []MergeVar{
+			{Name: "_NAME"},
+			{Name: "FIRST:NAME"},
+			{Name: "FIRST-NAME"},
+		},
+		MergeVars: []RecipientMergeVar{
+			{Recipient: "other@example.com", Vars: []MergeVar{{Name: "NAME"}}},
+		},
+		RecipientMetadata: []RecipientMetadata{
+			{Recipient: "other@example.com", Values: map[string]string{"id": "1"}},
+		},
+		Images: []*Image{{Name: "logo", Type: "application/pdf"}},
+	}
+	err := msg.Validate()
+	var me *MessageError
+	if !errors.As(err, &me) {
+		t.Errorf("expected *MessageError. Received: %v", err)
+		return
+	}
+	if len(me.Errs) != 11 {
+		t.Errorf("expected 11 errors. Received: %d: %v", len(me.Errs), err)
+	}
+}
+
+func TestSendWithValidation(t *testing.T) {
+	m := NewMandrill(TestAPIKey, WithValidation())
+	msg := &Message{
+		FromEmail: TestFromEmail,
+		To:        []Recipient{{Email: "accept@test.mandrillapp.com"}},
+		Tags:      []string{"_internal"},
+	}
+	if _, err := m.Messages().Send(msg, false, "", nil); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected validation error. Received: %v", err)
+	}
+	var me *MessageError
+	if _, err := m.Messages().SendTemplate("any-template", nil, msg, false, "", nil); !errors.As(err, &me) {
+		t.Errorf("expected *MessageError before calling api. Received: %v", err)
+	}
+}
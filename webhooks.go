@@ -1,14 +1,14 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Webhooks struct {
 	m *Mandrill
 }
 
-type webhooksResponse struct {
+type WebhooksResponse struct {
 	// a unique integer indentifier for the webhook
 	Id int `json:"id"`
 
@@ -41,61 +41,81 @@ type webhooksResponse struct {
 	LastError string `json:"last_error"`
 }
 
-func (w *Webhooks) List() ([]webhooksResponse, error) {
-	var ret []webhooksResponse
+func (w *Webhooks) List() ([]WebhooksResponse, error) {
+	return w.ListContext(context.Background())
+}
+
+// ListContext is like List but carries ctx through the request
+func (w *Webhooks) ListContext(ctx context.Context) ([]WebhooksResponse, error) {
+	var ret []WebhooksResponse
 	data := simpleRequest{w.m.APIKey}
-	body, err := w.m.execute("/webhooks/list.json", data)
+	body, err := w.m.execute(ctx, "/webhooks/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/webhooks/list.json", body, &ret); err != nil {
 		return ret, err
 	}
 
 	return ret, nil
 }
 
-func (w *Webhooks) Add(url string, description string, events []string) (webhooksResponse, error) {
-	var ret webhooksResponse
+func (w *Webhooks) Add(url string, description string, events []string) (WebhooksResponse, error) {
+	return w.AddContext(context.Background(), url, description, events)
+}
+
+// AddContext is like Add but carries ctx through the request
+func (w *Webhooks) AddContext(ctx context.Context, url string, description string, events []string) (WebhooksResponse, error) {
+	var ret WebhooksResponse
 	data := struct {
 		APIKey      string   `json:"key"`
 		URL         string   `json:"url"`
 		Description string   `json:"description,omitempty"`
 		Events      []string `json:"events,omitempty"`
 	}{w.m.APIKey, url, description, events}
-	body, err := w.m.execute("/webhooks/add.json", data)
+	body, err := w.m.execute(ctx, "/webhooks/add.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/webhooks/add.json", body, &ret); err != nil {
 		return ret, err
 	}
 
 	return ret, nil
 }
 
-func (w *Webhooks) Info(id int) (webhooksResponse, error) {
-	var ret webhooksResponse
+func (w *Webhooks) Info(id int) (WebhooksResponse, error) {
+	return w.InfoContext(context.Background(), id)
+}
+
+// InfoContext is like Info but carries ctx through the request
+func (w *Webhooks) InfoContext(ctx context.Context, id int) (WebhooksResponse, error) {
+	var ret WebhooksResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     int    `json:"id"`
 	}{w.m.APIKey, id}
-	body, err := w.m.execute("/webhooks/info.json", data)
+	body, err := w.m.execute(ctx, "/webhooks/info.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/webhooks/info.json", body, &ret); err != nil {
 		return ret, err
 	}
 
 	return ret, nil
 }
 
-func (w *Webhooks) Update(id int, url string, description string, events []string) (webhooksResponse, error) {
-	var ret webhooksResponse
+func (w *Webhooks) Update(id int, url string, description string, events []string) (WebhooksResponse, error) {
+	return w.UpdateContext(context.Background(), id, url, description, events)
+}
+
+// UpdateContext is like Update but carries ctx through the request
+func (w *Webhooks) UpdateContext(ctx context.Context, id int, url string, description string, events []string) (WebhooksResponse, error) {
+	var ret WebhooksResponse
 	data := struct {
 		APIKey      string   `json:"key"`
 		Id          int      `json:"id"`
@@ -103,30 +123,35 @@ func (w *Webhooks) Update(id int, url string, description string, events []strin
 		Description string   `json:"description,omitempty"`
 		Events      []string `json:"events,omitempty"`
 	}{w.m.APIKey, id, url, description, events}
-	body, err := w.m.execute("/webhooks/update.json", data)
+	body, err := w.m.execute(ctx, "/webhooks/update.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/webhooks/update.json", body, &ret); err != nil {
 		return ret, err
 	}
 
 	return ret, nil
 }
 
-func (w *Webhooks) Delete(id int) (webhooksResponse, error) {
-	var ret webhooksResponse
+func (w *Webhooks) Delete(id int) (WebhooksResponse, error) {
+	return w.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (w *Webhooks) DeleteContext(ctx context.Context, id int) (WebhooksResponse, error) {
+	var ret WebhooksResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Id     int    `json:"id"`
 	}{w.m.APIKey, id}
-	body, err := w.m.execute("/webhooks/delete.json", data)
+	body, err := w.m.execute(ctx, "/webhooks/delete.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	if err := json.Unmarshal(body, &ret); err != nil {
+	if err := decode("/webhooks/delete.json", body, &ret); err != nil {
 		return ret, err
 	}
 
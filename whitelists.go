@@ -1,7 +1,7 @@
 package mandrill
 
 import (
-	"encoding/json"
+	"context"
 )
 
 type Whitelists struct {
@@ -11,19 +11,24 @@ type Whitelists struct {
 // Add an email to your email rejection whitelist. If the address is currently
 // on your blacklist, that blacklist entry will be removed automatically.
 // comment: an optional description of why the email was whitelisted maxlength(255)
-func (w *Whitelists) Add(email string, comment string) (whitelistsAddResponse, error) {
-	var ret whitelistsAddResponse
+func (w *Whitelists) Add(email string, comment string) (WhitelistsAddResponse, error) {
+	return w.AddContext(context.Background(), email, comment)
+}
+
+// AddContext is like Add but carries ctx through the request
+func (w *Whitelists) AddContext(ctx context.Context, email string, comment string) (WhitelistsAddResponse, error) {
+	var ret WhitelistsAddResponse
 	data := struct {
 		APIKey  string `json:"key"`
 		Email   string `json:"email"`
 		Comment string `json:"comment,omitempty"`
 	}{w.m.APIKey, email, comment}
-	body, err := w.m.execute("/whitelists/add.json", data)
+	body, err := w.m.execute(ctx, "/whitelists/add.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/whitelists/add.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -31,23 +36,28 @@ func (w *Whitelists) Add(email string, comment string) (whitelistsAddResponse, e
 	return ret, nil
 }
 
-type whitelistsAddResponse struct {
+type WhitelistsAddResponse struct {
 	Email string `json:"email"`
 	Added bool   `json:"added"`
 }
 
-func (w *Whitelists) Delete(email string) (whitelistsDeleteResponse, error) {
-	var ret whitelistsDeleteResponse
+func (w *Whitelists) Delete(email string) (WhitelistsDeleteResponse, error) {
+	return w.DeleteContext(context.Background(), email)
+}
+
+// DeleteContext is like Delete but carries ctx through the request
+func (w *Whitelists) DeleteContext(ctx context.Context, email string) (WhitelistsDeleteResponse, error) {
+	var ret WhitelistsDeleteResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Email  string `json:"email"`
 	}{w.m.APIKey, email}
-	body, err := w.m.execute("/whitelists/delete.json", data)
+	body, err := w.m.execute(ctx, "/whitelists/delete.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/whitelists/delete.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -55,25 +65,30 @@ func (w *Whitelists) Delete(email string) (whitelistsDeleteResponse, error) {
 	return ret, nil
 }
 
-type whitelistsDeleteResponse struct {
+type WhitelistsDeleteResponse struct {
 	Email   string `json:"email"`
 	Deleted bool   `json:"deleted"`
 }
 
 // List retrieves up to 1000 of your email rejection whitelist
 // providee an email address or search prefix to limit the results
-func (w *Whitelists) List(email string) ([]whitelistsListResponse, error) {
-	var ret []whitelistsListResponse
+func (w *Whitelists) List(email string) ([]WhitelistsListResponse, error) {
+	return w.ListContext(context.Background(), email)
+}
+
+// ListContext is like List but carries ctx through the request
+func (w *Whitelists) ListContext(ctx context.Context, email string) ([]WhitelistsListResponse, error) {
+	var ret []WhitelistsListResponse
 	data := struct {
 		APIKey string `json:"key"`
 		Email  string `json:"email,omitempty"`
 	}{w.m.APIKey, email}
-	body, err := w.m.execute("/whitelists/list.json", data)
+	body, err := w.m.execute(ctx, "/whitelists/list.json", data)
 	if err != nil {
 		return ret, err
 	}
 
-	err = json.Unmarshal(body, &ret)
+	err = decode("/whitelists/list.json", body, &ret)
 	if err != nil {
 		return ret, err
 	}
@@ -81,7 +96,7 @@ func (w *Whitelists) List(email string) ([]whitelistsListResponse, error) {
 	return ret, nil
 }
 
-type whitelistsListResponse struct {
+type WhitelistsListResponse struct {
 	// email that is whitelisted
 	Email string `json:"email"`
 
@@ -23,7 +23,7 @@ func TestWhitelists(t *testing.T) {
 		t.Error("failed to retrieve whitelisted email")
 		return
 	} else if resp[0].Email != e || resp[0].Detail != "test whitelist" {
-		t.Error("failed to retrieve whitelisted email. Response: %+v", resp)
+		t.Errorf("failed to retrieve whitelisted email. Response: %+v", resp)
 		return
 	}
 